@@ -0,0 +1,82 @@
+package jumpserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+)
+
+// lookupFunc resolves the name of an object to its ID.
+type lookupFunc func(ctx context.Context, api *client.Client, name string) (string, error)
+
+// lookupCache memoizes name-to-ID lookups for the lifetime of the provider,
+// i.e. one plan or apply. Concurrent lookups of the same key share a single
+// API call, and failed lookups are not cached.
+type lookupCache struct {
+	mu      sync.Mutex
+	entries map[lookupKey]*lookupEntry
+}
+
+type lookupKey struct {
+	orgID string
+	kind  string
+	name  string
+}
+
+type lookupEntry struct {
+	done chan struct{}
+	id   string
+	err  error
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{entries: map[lookupKey]*lookupEntry{}}
+}
+
+// get returns the cached ID for kind/name in the organization of api,
+// calling fetch if it is not known yet. Names are compared ignoring case,
+// like the lookups themselves.
+func (lc *lookupCache) get(ctx context.Context, api *client.Client, kind, name string, fetch lookupFunc) (string, error) {
+	key := lookupKey{orgID: api.OrgID(), kind: kind, name: strings.ToLower(name)}
+
+	lc.mu.Lock()
+	if entry, ok := lc.entries[key]; ok {
+		lc.mu.Unlock()
+		select {
+		case <-entry.done:
+			return entry.id, entry.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	entry := &lookupEntry{done: make(chan struct{})}
+	lc.entries[key] = entry
+	lc.mu.Unlock()
+
+	entry.id, entry.err = fetch(ctx, api, name)
+	if entry.err != nil {
+		lc.mu.Lock()
+		if lc.entries[key] == entry {
+			delete(lc.entries, key)
+		}
+		lc.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.id, entry.err
+}
+
+// invalidate drops every cached lookup of kind, in all organizations. It is
+// called when the provider itself creates, renames, moves or deletes objects
+// of that kind.
+func (lc *lookupCache) invalidate(kind string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	for key := range lc.entries {
+		if key.kind == kind {
+			delete(lc.entries, key)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/twindagger/httpsig.v1"
+)
+
+// tokenRefreshMargin is how long before its expiry a bearer token is renewed.
+const tokenRefreshMargin = time.Minute
+
+// Token types accepted by JumpServer: bearer tokens obtained by logging in
+// or issued to a user, and long-lived private tokens.
+const (
+	TokenTypeBearer  = "Bearer"
+	TokenTypePrivate = "Token"
+)
+
+// authPath is the endpoint exchanging credentials for a bearer token.
+const authPath = "/api/v1/authentication/auth/"
+
+// defaultMFAChallengePath is where the OTP code is submitted when JumpServer
+// does not say so in its MFA challenge.
+const defaultMFAChallengePath = "/api/v1/authentication/mfa/challenge/"
+
+// MFA holds the second factor used when JumpServer requires one at login.
+// TOTPSecret generates a fresh code for every login, so tokens can be
+// renewed; OTPCode is a one-off code and only serves the first login.
+type MFA struct {
+	OTPCode    string
+	TOTPSecret string
+}
+
+// Login exchanges a username and password for a bearer token, which is then
+// used for every subsequent request. The credentials are kept so the token
+// can be renewed when it expires or is rejected. mfa completes the MFA
+// challenge of accounts with OTP enforced.
+func (c *Client) Login(ctx context.Context, username, password string, mfa MFA) error {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	c.session.username = username
+	c.session.password = password
+	c.session.mfa = mfa
+	return c.login(ctx)
+}
+
+// login fetches a new bearer token. c.session.mu must be held.
+func (c *Client) login(ctx context.Context) error {
+	credentials := map[string]string{
+		"username": c.session.username,
+		"password": c.session.password,
+	}
+
+	var result map[string]interface{}
+	if err := c.do(ctx, http.MethodPost, authPath, credentials, &result, false); err != nil {
+		// Some versions answer the MFA challenge with an error status.
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || json.Unmarshal([]byte(apiErr.Body), &result) != nil || result["error"] != "mfa_required" {
+			return err
+		}
+	}
+
+	// Accounts with MFA enforced get a challenge instead of a token. Once
+	// it is answered, the session cookie carries the second factor and the
+	// same credentials are exchanged again.
+	if result["error"] == "mfa_required" {
+		if err := c.answerMFAChallenge(ctx, result); err != nil {
+			return err
+		}
+		result = nil
+		if err := c.do(ctx, http.MethodPost, authPath, credentials, &result, false); err != nil {
+			return err
+		}
+	}
+
+	token, ok := result["token"].(string)
+	if !ok {
+		if msg, ok := result["msg"].(string); ok && msg != "" {
+			return fmt.Errorf("unable to fetch token from %s%s: %s", c.baseURL, authPath, msg)
+		}
+		return fmt.Errorf("unable to fetch token from %s%s", c.baseURL, authPath)
+	}
+	c.session.token = token
+	c.session.tokenExpiry = tokenExpiry(result)
+	return nil
+}
+
+// answerMFAChallenge submits an OTP code to the MFA challenge returned by a
+// login attempt. c.session.mu must be held.
+func (c *Client) answerMFAChallenge(ctx context.Context, challenge map[string]interface{}) error {
+	mfa := c.session.mfa
+	if mfa.OTPCode == "" && mfa.TOTPSecret == "" {
+		return fmt.Errorf("user %q has MFA enabled: set otp_code or totp_secret to log in", c.session.username)
+	}
+
+	path := defaultMFAChallengePath
+	if data, ok := challenge["data"].(map[string]interface{}); ok {
+		if choices, ok := data["choices"].([]interface{}); ok && !containsValue(choices, "otp") {
+			return fmt.Errorf("user %q must log in with one of the MFA methods %v, only OTP is supported", c.session.username, choices)
+		}
+		if url, ok := data["url"].(string); ok && strings.HasPrefix(url, "/api/") {
+			path = url
+		}
+	}
+
+	code := mfa.OTPCode
+	if mfa.TOTPSecret != "" {
+		var err error
+		if code, err = totpCode(mfa.TOTPSecret, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	body := map[string]string{
+		"type": "otp",
+		"code": code,
+	}
+	if err := c.do(ctx, http.MethodPost, path, body, nil, false); err != nil {
+		return fmt.Errorf("MFA challenge failed: %w", err)
+	}
+	return nil
+}
+
+func containsValue(values []interface{}, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) canLogin() bool {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	return c.session.username != ""
+}
+
+// refreshToken logs in again after JumpServer rejected the given token. If
+// another request already replaced it, the new token is kept.
+func (c *Client) refreshToken(ctx context.Context, rejected string) error {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	if c.session.token != rejected {
+		return nil
+	}
+	return c.login(ctx)
+}
+
+// authorize adds credentials to r and returns the bearer token used, if any.
+// Only tokens obtained by logging in are returned, as they are the ones that
+// can be renewed.
+func (c *Client) authorize(ctx context.Context, r *http.Request) (string, error) {
+	token, err := c.currentToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return token, nil
+	}
+	if c.token != "" {
+		r.Header.Set("Authorization", c.tokenType+" "+c.token)
+		return "", nil
+	}
+	if c.accessKey != "" && c.secretKey != "" {
+		return "", signReq(r, c.accessKey, c.secretKey)
+	}
+	return "", nil
+}
+
+// currentToken returns the bearer token, renewing it first when it is about
+// to expire.
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	s := c.session
+	if s.token != "" && s.username != "" && !s.tokenExpiry.IsZero() && time.Until(s.tokenExpiry) < tokenRefreshMargin {
+		if err := c.login(ctx); err != nil {
+			return "", fmt.Errorf("refreshing expired token: %w", err)
+		}
+	}
+	return s.token, nil
+}
+
+// tokenExpiry reads the token expiry from an authentication response. It
+// accepts "expiration" as a lifetime in seconds, a Unix time or a timestamp,
+// and the "date_expired" timestamp returned by most JumpServer versions.
+func tokenExpiry(result map[string]interface{}) time.Time {
+	for _, key := range []string{"expiration", "date_expired"} {
+		switch v := result[key].(type) {
+		case float64:
+			if v > 1e9 {
+				return time.Unix(int64(v), 0)
+			}
+			return time.Now().Add(time.Duration(v) * time.Second)
+		case string:
+			if seconds, err := strconv.Atoi(v); err == nil {
+				return time.Now().Add(time.Duration(seconds) * time.Second)
+			}
+			for _, layout := range []string{time.RFC3339, "2006/01/02 15:04:05 -0700", "2006-01-02 15:04:05 -0700"} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func signReq(r *http.Request, accessKey string, secretKey string) error {
+	headers := []string{"(request-target)", "date"}
+	gmtFmt := "Mon, 02 Jan 2006 15:04:05 GMT"
+	r.Header.Set("Date", time.Now().UTC().Format(gmtFmt))
+
+	signer, err := httpsig.NewRequestSigner(accessKey, secretKey, "hmac-sha256")
+	if err != nil {
+		return err
+	}
+	return signer.SignRequest(r, headers, nil)
+}
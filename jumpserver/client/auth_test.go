@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// authServer issues a new token on every login and answers other requests
+// with 401 for the first unauthorized requests, then with 200.
+func authServer(t *testing.T, unauthorized int32) (srv *httptest.Server, logins, requests *int32) {
+	t.Helper()
+	logins, requests = new(int32), new(int32)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == authPath {
+			n := atomic.AddInt32(logins, 1)
+			fmt.Fprintf(w, `{"token":"token-%d"}`, n)
+			return
+		}
+		if atomic.AddInt32(requests, 1) <= unauthorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"detail":"Authentication credentials were not provided."}`))
+			return
+		}
+		if got, want := r.Header.Get("Authorization"), fmt.Sprintf("Bearer token-%d", atomic.LoadInt32(logins)); got != want {
+			t.Errorf("got Authorization %q, want %q", got, want)
+		}
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, logins, requests
+}
+
+func TestReloginAfterUnauthorized(t *testing.T) {
+	srv, logins, requests := authServer(t, 1)
+	c := newTestClient(t, srv.URL, 0)
+	if err := c.Login(context.Background(), "admin", "password", MFA{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Get(context.Background(), "/api/v1/users/profile/", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(logins); got != 2 {
+		t.Errorf("got %d logins, want 2", got)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestReloginOnlyOnce(t *testing.T) {
+	srv, logins, requests := authServer(t, 10)
+	c := newTestClient(t, srv.URL, 0)
+	if err := c.Login(context.Background(), "admin", "password", MFA{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.Get(context.Background(), "/api/v1/users/profile/", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got error %v, want a 401 APIError", err)
+	}
+	if got := atomic.LoadInt32(logins); got != 2 {
+		t.Errorf("got %d logins, want 2", got)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
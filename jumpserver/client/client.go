@@ -0,0 +1,321 @@
+// Package client implements the JumpServer REST API client shared by every
+// resource of the provider.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Config holds the settings used to build a Client.
+type Config struct {
+	BaseURL       string
+	AccessKey     string
+	SecretKey     string
+	SkipTLSVerify bool
+
+	// Token is a pre-issued token sent as "Authorization: <TokenType> <Token>".
+	// TokenType defaults to TokenTypeBearer.
+	Token     string
+	TokenType string
+
+	// CACertPEM adds trusted CA certificates to the system pool.
+	// ClientCertPEM and ClientKeyPEM enable mutual TLS. TLSServerName
+	// overrides the name the server certificate is verified against.
+	CACertPEM     []byte
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+	TLSServerName string
+
+	// ProxyURL is the HTTP(S) proxy requests go through. When empty the
+	// standard proxy environment variables are used.
+	ProxyURL string
+
+	// OrgID is the organization requests are made in, sent as the X-JMS-ORG
+	// header. Empty means the user's default organization.
+	OrgID string
+
+	// RequestTimeout bounds a single HTTP request, including reading the
+	// response body. Zero means no timeout.
+	RequestTimeout time.Duration
+
+	// MaxRetries is the number of times a request failing with a transient
+	// error is retried. Zero disables retries.
+	MaxRetries   int
+	RetryMinWait time.Duration
+	RetryMaxWait time.Duration
+
+	// MaxRequestsPerSecond and MaxConcurrentRequests throttle every request
+	// made by the client. Zero means unlimited.
+	MaxRequestsPerSecond  float64
+	MaxConcurrentRequests int
+}
+
+// Client talks to the JumpServer API. A single Client, and therefore a single
+// transport, is shared by every resource. WithOrg returns views of it scoped
+// to another organization that share the same transport and credentials.
+type Client struct {
+	baseURL    string
+	accessKey  string
+	secretKey  string
+	token      string
+	tokenType  string
+	orgID      string
+	httpClient *http.Client
+	session    *session
+	limiter    *limiter
+
+	maxRetries   int
+	retryMinWait time.Duration
+	retryMaxWait time.Duration
+}
+
+// session holds the login credentials and the bearer token obtained with
+// them, which is refreshed on expiry or when JumpServer rejects it.
+type session struct {
+	mu          sync.Mutex
+	username    string
+	password    string
+	mfa         MFA
+	token       string
+	tokenExpiry time.Time
+}
+
+// New returns a Client for the given configuration.
+func New(cfg Config) (*Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The MFA login flow is tied to the JumpServer session cookie.
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimRight(cfg.BaseURL, "/"),
+		accessKey:    cfg.AccessKey,
+		secretKey:    cfg.SecretKey,
+		token:        cfg.Token,
+		tokenType:    cfg.TokenType,
+		orgID:        cfg.OrgID,
+		httpClient:   &http.Client{Transport: transport, Jar: jar, Timeout: cfg.RequestTimeout},
+		session:      &session{},
+		limiter:      newLimiter(cfg.MaxRequestsPerSecond, cfg.MaxConcurrentRequests),
+		maxRetries:   cfg.MaxRetries,
+		retryMinWait: cfg.RetryMinWait,
+		retryMaxWait: cfg.RetryMaxWait,
+	}
+	if c.tokenType == "" {
+		c.tokenType = TokenTypeBearer
+	}
+	if c.retryMinWait <= 0 {
+		c.retryMinWait = DefaultRetryMinWait
+	}
+	if c.retryMaxWait < c.retryMinWait {
+		c.retryMaxWait = c.retryMinWait
+	}
+	return c, nil
+}
+
+// BaseURL returns the JumpServer URL the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// OrgID returns the organization the client makes requests in.
+func (c *Client) OrgID() string {
+	return c.orgID
+}
+
+// WithOrg returns a client making requests in the given organization. An
+// empty orgID returns c unchanged.
+func (c *Client) WithOrg(orgID string) *Client {
+	if orgID == "" || orgID == c.orgID {
+		return c
+	}
+	scoped := *c
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// Get sends a GET request and decodes the JSON response into out.
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post sends body as JSON with a POST request and decodes the response into out.
+func (c *Client) Post(ctx context.Context, path string, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+// Put sends body as JSON with a PUT request and decodes the response into out.
+func (c *Client) Put(ctx context.Context, path string, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPut, path, body, out)
+}
+
+// Patch sends body as JSON with a PATCH request and decodes the response into out.
+func (c *Client) Patch(ctx context.Context, path string, body, out interface{}) error {
+	return c.Do(ctx, http.MethodPatch, path, body, out)
+}
+
+// Delete sends a DELETE request.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	return c.Do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// Do performs an authenticated API request. A nil body sends no payload and a
+// nil out discards the response. Transient failures are retried according to
+// the client's retry settings; non-2xx responses are returned as *APIError.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	return c.do(ctx, method, path, body, out, true)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, authenticated bool) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding %s %s request: %w", method, path, err)
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.send(ctx, method, path, payload, authenticated)
+	if err != nil {
+		tflog.Debug(ctx, "JumpServer API request failed", map[string]interface{}{
+			"method":      method,
+			"path":        path,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"error":       err.Error(),
+		})
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading %s %s response: %w", method, path, err)
+	}
+	logExchange(ctx, method, path, resp.StatusCode, payload, respBody, time.Since(start))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(method, path, resp.StatusCode, respBody)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// send issues the request, retrying transient failures with exponential
+// backoff. A request rejected with 401 is replayed once after logging in
+// again. The returned response body must be closed by the caller.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, authenticated bool) (*http.Response, error) {
+	reauthenticated := false
+	for attempt := 0; ; {
+		req, token, err := c.newRequest(ctx, method, path, payload, authenticated)
+		if err != nil {
+			return nil, err
+		}
+
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			release()
+		} else {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+		}
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && token != "" && !reauthenticated && c.canLogin() {
+			discard(resp)
+			tflog.Debug(ctx, "JumpServer rejected the token, logging in again", map[string]interface{}{
+				"method": method,
+				"path":   path,
+			})
+			if err := c.refreshToken(ctx, token); err != nil {
+				return nil, err
+			}
+			reauthenticated = true
+			continue
+		}
+		if attempt >= c.maxRetries || !shouldRetry(ctx, method, resp, err) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		fields := map[string]interface{}{
+			"method":  method,
+			"path":    path,
+			"attempt": attempt + 1,
+			"wait_ms": wait.Milliseconds(),
+		}
+		if resp != nil {
+			fields["status"] = resp.StatusCode
+			discard(resp)
+		} else {
+			fields["error"] = err.Error()
+		}
+		tflog.Debug(ctx, "Retrying JumpServer API request", fields)
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		attempt++
+	}
+}
+
+// newRequest builds a request for path. When authenticated is set the
+// request is authorized and the bearer token used, if any, is returned.
+func (c *Client) newRequest(ctx context.Context, method, path string, payload []byte, authenticated bool) (*http.Request, string, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.orgID != "" {
+		req.Header.Set("X-JMS-ORG", c.orgID)
+	}
+	if !authenticated {
+		return req, "", nil
+	}
+
+	token, err := c.authorize(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	return req, token, nil
+}
+
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, then succeeds.
+// It returns the server and a counter of the requests it received.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newTestClient(t *testing.T, baseURL string, maxRetries int) *Client {
+	t.Helper()
+	c, err := New(Config{
+		BaseURL:      baseURL,
+		Token:        "token",
+		MaxRetries:   maxRetries,
+		RetryMinWait: time.Millisecond,
+		RetryMaxWait: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRetryIdempotentMethods(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		for _, status := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusTooManyRequests} {
+			srv, calls := flakyServer(t, 3, status, nil)
+			c := newTestClient(t, srv.URL, 3)
+
+			if err := c.Do(context.Background(), method, "/api/v1/assets/hosts/1/", nil, nil); err != nil {
+				t.Errorf("%s after three %d: unexpected error: %v", method, status, err)
+			}
+			if got := atomic.LoadInt32(calls); got != 4 {
+				t.Errorf("%s after three %d: got %d requests, want 4", method, status, got)
+			}
+		}
+	}
+}
+
+func TestRetryStopsAtMaxRetries(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	c := newTestClient(t, srv.URL, 2)
+
+	err := c.Get(context.Background(), "/api/v1/assets/hosts/", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got error %v, want a 503 APIError", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		want   int32
+	}{
+		{http.StatusTooManyRequests, 3},
+		{http.StatusBadGateway, 1},
+		{http.StatusServiceUnavailable, 1},
+		{http.StatusGatewayTimeout, 1},
+	} {
+		srv, calls := flakyServer(t, 2, tc.status, nil)
+		c := newTestClient(t, srv.URL, 3)
+
+		err := c.Post(context.Background(), "/api/v1/assets/hosts/", map[string]interface{}{"name": "web"}, nil)
+		if tc.status == http.StatusTooManyRequests && err != nil {
+			t.Errorf("POST after two 429: unexpected error: %v", err)
+		}
+		if tc.status != http.StatusTooManyRequests && err == nil {
+			t.Errorf("POST after a %d: expected an error", tc.status)
+		}
+		if got := atomic.LoadInt32(calls); got != tc.want {
+			t.Errorf("POST after %d: got %d requests, want %d", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	c := newTestClient(t, srv.URL, 3)
+
+	start := time.Now()
+	if err := c.Get(context.Background(), "/api/v1/assets/hosts/", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s of Retry-After", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestRetryCancelledContext(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable, http.Header{"Retry-After": {"60"}})
+	c := newTestClient(t, srv.URL, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Get(ctx, "/api/v1/assets/hosts/", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled request returned after %s", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
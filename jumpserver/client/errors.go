@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// APIError is returned for any non-2xx response from JumpServer. When the body
+// is a Django REST framework error document it is parsed into Detail and
+// FieldErrors.
+type APIError struct {
+	StatusCode  int
+	Method      string
+	Path        string
+	Detail      string
+	FieldErrors []FieldError
+	Body        string
+}
+
+// FieldError is a validation message reported for a single request field.
+// Field holds the path to the field, made of object keys (string) and list
+// indexes (int), e.g. ["accounts", 0, "secret"].
+type FieldError struct {
+	Field   []interface{}
+	Message string
+}
+
+// FieldName returns the dotted form of the field path, e.g. "accounts.0.secret".
+func (f FieldError) FieldName() string {
+	parts := make([]string, len(f.Field))
+	for i, step := range f.Field {
+		parts[i] = fmt.Sprint(step)
+	}
+	return strings.Join(parts, ".")
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+
+	var details []string
+	if e.Detail != "" {
+		details = append(details, e.Detail)
+	}
+	for _, fe := range e.FieldErrors {
+		details = append(details, fe.FieldName()+": "+fe.Message)
+	}
+	if len(details) == 0 && e.Body != "" {
+		details = append(details, e.Body)
+	}
+	if len(details) > 0 {
+		msg += ": " + strings.Join(details, "; ")
+	}
+	return msg
+}
+
+func newAPIError(method, path string, status int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     method,
+		Path:       path,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err == nil {
+		apiErr.parse(doc)
+	}
+	return apiErr
+}
+
+// nonFieldKeys are the keys DRF and JumpServer use for errors that are not
+// tied to a particular field.
+var nonFieldKeys = map[string]bool{
+	"detail":           true,
+	"error":            true,
+	"msg":              true,
+	"message":          true,
+	"non_field_errors": true,
+}
+
+func (e *APIError) parse(doc interface{}) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var details []string
+		for _, k := range keys {
+			if nonFieldKeys[k] {
+				details = append(details, messages(v[k])...)
+				continue
+			}
+			e.collectFieldErrors([]interface{}{k}, v[k])
+		}
+		e.Detail = strings.Join(details, " ")
+	case []interface{}:
+		e.Detail = strings.Join(messages(v), " ")
+	case string:
+		e.Detail = v
+	}
+}
+
+func (e *APIError) collectFieldErrors(field []interface{}, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			e.collectFieldErrors(appendStep(field, k), v[k])
+		}
+	case []interface{}:
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				e.collectFieldErrors(appendStep(field, i), item)
+			default:
+				e.collectFieldErrors(field, item)
+			}
+		}
+	case nil:
+	default:
+		e.FieldErrors = append(e.FieldErrors, FieldError{
+			Field:   field,
+			Message: fmt.Sprint(v),
+		})
+	}
+}
+
+func appendStep(field []interface{}, step interface{}) []interface{} {
+	next := make([]interface{}, len(field), len(field)+1)
+	copy(next, field)
+	return append(next, step)
+}
+
+func messages(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, messages(item)...)
+		}
+		return result
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
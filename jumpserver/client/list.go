@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageSize is the number of objects requested per page when listing
+// a collection.
+const DefaultPageSize = 100
+
+// page is the envelope of a paginated JumpServer list response.
+type page struct {
+	Count   int                      `json:"count"`
+	Next    *string                  `json:"next"`
+	Results []map[string]interface{} `json:"results"`
+}
+
+// List calls fn for every object of the collection at path, passing query as
+// filters to the server. Both plain list responses and paginated
+// {count, next, results} envelopes are accepted, and next links are followed
+// until the collection is exhausted or fn returns false. A query already
+// present in path is merged with query, which takes precedence.
+func (c *Client) List(ctx context.Context, path string, query url.Values, fn func(object map[string]interface{}) bool) error {
+	u, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid list path %q: %w", path, err)
+	}
+	q := u.Query()
+	for k, v := range query {
+		q[k] = v
+	}
+	if q.Get("limit") == "" {
+		q.Set("limit", strconv.Itoa(DefaultPageSize))
+	}
+	u.RawQuery = q.Encode()
+	next := u.String()
+
+	for next != "" {
+		var raw json.RawMessage
+		if err := c.Get(ctx, next, &raw); err != nil {
+			return err
+		}
+
+		var objects []map[string]interface{}
+		nextURL := ""
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+			if err := json.Unmarshal(trimmed, &objects); err != nil {
+				return fmt.Errorf("decoding %s list: %w", path, err)
+			}
+		} else {
+			var p page
+			if err := json.Unmarshal(trimmed, &p); err != nil {
+				return fmt.Errorf("decoding %s list: %w", path, err)
+			}
+			objects = p.Results
+			if p.Next != nil {
+				nextURL = *p.Next
+			}
+		}
+
+		for _, object := range objects {
+			if !fn(object) {
+				return nil
+			}
+		}
+
+		next, err = c.relativePath(nextURL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relativePath turns a next link, which JumpServer returns as an absolute
+// URL that may carry the wrong host behind a proxy, into a path relative to
+// the client base URL.
+func (c *Client) relativePath(link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid next link %q: %w", link, err)
+	}
+
+	path := u.RequestURI()
+	if base, err := url.Parse(c.baseURL); err == nil && base.Path != "" {
+		path = strings.TrimPrefix(path, strings.TrimRight(base.Path, "/"))
+	}
+	return path, nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// maxLoggedBody is the size above which logged bodies are truncated.
+const maxLoggedBody = 4096
+
+// redacted replaces the value of sensitive fields in logged bodies.
+const redacted = "***REDACTED***"
+
+// sensitiveKeys are the JSON fields whose values are never logged, at any
+// depth of a request or response body.
+var sensitiveKeys = map[string]bool{
+	"password":    true,
+	"secret":      true,
+	"private_key": true,
+	"token":       true,
+	"passphrase":  true,
+	"otp_code":    true,
+	"code":        true,
+}
+
+// logExchange logs a completed API call under TF_LOG=DEBUG, with both bodies
+// redacted.
+func logExchange(ctx context.Context, method, path string, status int, reqBody, respBody []byte, elapsed time.Duration) {
+	fields := map[string]interface{}{
+		"method":      method,
+		"path":        path,
+		"status":      status,
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if len(reqBody) > 0 {
+		fields["request_body"] = redactBody(reqBody)
+	}
+	if len(respBody) > 0 {
+		fields["response_body"] = redactBody(respBody)
+	}
+	tflog.Debug(ctx, "JumpServer API request", fields)
+}
+
+// redactBody returns a loggable form of a JSON body with the values of
+// sensitive fields masked. Bodies that are not JSON are not logged, as they
+// cannot be redacted reliably.
+func redactBody(body []byte) string {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Sprintf("<%d bytes of non-JSON content>", len(body))
+	}
+
+	out, err := json.Marshal(redact(doc))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	if len(out) > maxLoggedBody {
+		return string(out[:maxLoggedBody]) + "...(truncated)"
+	}
+	return string(out)
+}
+
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if sensitiveKeys[strings.ToLower(k)] && item != nil && item != "" {
+				result[k] = redacted
+				continue
+			}
+			result[k] = redact(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = redact(item)
+		}
+		return result
+	}
+	return v
+}
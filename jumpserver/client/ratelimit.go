@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// limiter throttles the requests of a Client and all its organization views:
+// a token bucket caps the request rate and a semaphore caps the number of
+// requests in flight. Requests wait for their turn instead of failing.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second, 0 means unlimited
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	slots chan struct{} // nil means no concurrency cap
+}
+
+func newLimiter(requestsPerSecond float64, maxConcurrent int) *limiter {
+	l := &limiter{rate: requestsPerSecond}
+	if requestsPerSecond > 0 {
+		// Allow up to one second worth of requests to go out at once.
+		l.burst = requestsPerSecond
+		if l.burst < 1 {
+			l.burst = 1
+		}
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// acquire blocks until the request may be sent. The returned function must
+// be called once the response has been consumed.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if err := l.waitToken(ctx); err != nil {
+		return nil, err
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.slots }) }, nil
+}
+
+func (l *limiter) waitToken(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		// Give the reserved token back to the requests still waiting.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// releaseOnClose releases the limiter slot of a request when its response
+// body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
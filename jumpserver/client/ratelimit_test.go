@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterBurst(t *testing.T) {
+	l := newLimiter(10, 0)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("burst of 10 requests took %s, want no wait", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("request over the burst went out after %s, want about 100ms", elapsed)
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	l := newLimiter(10, 0)
+	for i := 0; i < 10; i++ {
+		if _, err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 250ms refill two and a half tokens.
+	time.Sleep(250 * time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Millisecond {
+		t.Errorf("refilled requests took %s, want no wait", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("request over the refill went out after %s, want about 50ms", elapsed)
+	}
+}
+
+func TestLimiterCancelledContext(t *testing.T) {
+	l := newLimiter(1, 0)
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("cancelled request returned after %s, want about 50ms", elapsed)
+	}
+
+	// The token reserved by the cancelled request is given back.
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("got %.2f tokens after cancellation, want the reservation returned", tokens)
+	}
+}
+
+func TestLimiterConcurrencyCancelledContext(t *testing.T) {
+	l := newLimiter(0, 1)
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Errorf("slot not available after release: %v", err)
+	}
+}
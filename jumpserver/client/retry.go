@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default retry settings, used when the corresponding Config field is zero.
+const (
+	DefaultRetryMinWait = 1 * time.Second
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// retryableStatus lists the responses that indicate a transient failure of
+// JumpServer or the proxy in front of it.
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry decides whether a request may be sent again. Idempotent
+// requests are retried on transport errors and transient statuses; other
+// requests only on 429, which JumpServer returns before doing any work.
+func shouldRetry(ctx context.Context, method string, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isIdempotent(method)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return isIdempotent(method) && retryableStatus[resp.StatusCode]
+}
+
+// backoff returns how long to wait before the given retry attempt (starting
+// at 0). A Retry-After header on resp takes precedence over the computed
+// exponential delay.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait
+		}
+	}
+
+	wait := float64(c.retryMinWait) * math.Pow(2, float64(attempt))
+	if wait > float64(c.retryMaxWait) || math.IsInf(wait, 0) {
+		wait = float64(c.retryMaxWait)
+	}
+	// Equal jitter: keep half of the delay and randomize the rest so that
+	// parallel resources don't retry in lockstep.
+	half := time.Duration(wait / 2)
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
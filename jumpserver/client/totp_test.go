@@ -0,0 +1,49 @@
+package client
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// TestTOTPCode checks the SHA-1 test vectors of RFC 6238, appendix B,
+// truncated to the 6 digits JumpServer uses.
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	} {
+		got, err := totpCode(secret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatalf("T=%d: unexpected error: %v", tc.unix, err)
+		}
+		if got != tc.want {
+			t.Errorf("T=%d: got %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeTOTPSecret(t *testing.T) {
+	for _, secret := range []string{"GEZDGNBVGY3TQOJQ", "gezd gnbv gy3t qojq", "GEZDGNBVGY3TQOJQ===="} {
+		key, err := decodeTOTPSecret(secret)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", secret, err)
+			continue
+		}
+		if string(key) != "1234567890" {
+			t.Errorf("%q: got key %q, want %q", secret, key, "1234567890")
+		}
+	}
+	if err := ValidateTOTPSecret("not-base32!"); err == nil {
+		t.Error("expected an error for an invalid secret")
+	}
+}
@@ -0,0 +1,184 @@
+package jumpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// credentials are the authentication settings of the provider.
+type credentials struct {
+	username  string
+	password  string
+	accessKey string
+	secretKey string
+	token     string
+	tokenType string
+
+	otpCode    string
+	totpSecret string
+}
+
+// validateCredentials checks that exactly one authentication method is
+// configured, and completely, so a half-configured provider fails before
+// anything is sent to JumpServer.
+func validateCredentials(creds credentials) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pairs := []struct {
+		first, second           string
+		firstValue, secondValue string
+	}{
+		{"access_key", "secret_key", creds.accessKey, creds.secretKey},
+		{"username", "password", creds.username, creds.password},
+	}
+	complete := 0
+	for _, p := range pairs {
+		switch {
+		case p.firstValue != "" && p.secondValue != "":
+			complete++
+		case p.firstValue != "":
+			diags = append(diags, missingCredential(p.second, p.first))
+		case p.secondValue != "":
+			diags = append(diags, missingCredential(p.first, p.second))
+		}
+	}
+	if creds.token != "" {
+		complete++
+		if creds.tokenType != client.TokenTypeBearer && creds.tokenType != client.TokenTypePrivate {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid token_type",
+				Detail:        fmt.Sprintf("token_type must be %q or %q, got %q.", client.TokenTypeBearer, client.TokenTypePrivate, creds.tokenType),
+				AttributePath: cty.GetAttrPath("token_type"),
+			})
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if (creds.otpCode != "" || creds.totpSecret != "") && creds.username == "" {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "MFA requires username and password",
+			Detail:   "otp_code and totp_secret complete a username and password login. Remove them when authenticating with an access key pair.",
+		})
+	}
+	if creds.totpSecret != "" {
+		if err := client.ValidateTOTPSecret(creds.totpSecret); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid totp_secret",
+				Detail:        fmt.Sprintf("%s. The secret must be the base32 key shown when the authenticator was enrolled.", err),
+				AttributePath: cty.GetAttrPath("totp_secret"),
+			})
+		}
+	}
+
+	switch complete {
+	case 0:
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Missing Jumpserver credentials",
+			Detail:   "Set one of access_key and secret_key, username and password, or token, in the provider configuration, the JUMPSERVER_* environment variables or the selected profile.",
+		})
+	case 1:
+	default:
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Conflicting Jumpserver credentials",
+			Detail:   "More than one of an access key pair, a username and password, and a token are set. Configure only one authentication method.",
+		})
+	}
+	return diags
+}
+
+func missingCredential(missing, set string) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity:      diag.Error,
+		Summary:       fmt.Sprintf("Missing %s", missing),
+		Detail:        fmt.Sprintf("%s is set but %s is not. Both are required to authenticate with Jumpserver.", set, missing),
+		AttributePath: cty.GetAttrPath(missing),
+	}
+}
+
+// checkConnection makes a cheap authenticated request so that an unreachable
+// server or rejected credentials are reported when the provider is
+// configured rather than by the first resource.
+func checkConnection(ctx context.Context, api *client.Client) diag.Diagnostics {
+	var profile map[string]interface{}
+	if err := api.Get(ctx, "/api/v1/users/profile/", &profile); err != nil {
+		return connectionDiagnostics(api.BaseURL(), err)
+	}
+	return nil
+}
+
+// connectionDiagnostics turns an error from the first requests made to
+// JumpServer into a diagnostic saying what is most likely wrong.
+func connectionDiagnostics(baseURL string, err error) diag.Diagnostics {
+	summary, hint := "Failed to connect to Jumpserver", ""
+
+	var apiErr *client.APIError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+
+	switch {
+	case errors.As(err, &apiErr):
+		switch {
+		case strings.Contains(apiErr.Body, "HTTP request to an HTTPS server"):
+			summary = "Jumpserver requires HTTPS"
+			hint = "The server only accepts TLS connections. Use https in base_url."
+		case apiErr.StatusCode == http.StatusUnauthorized,
+			apiErr.StatusCode == http.StatusBadRequest && strings.HasPrefix(apiErr.Path, "/api/v1/authentication/"):
+			summary = "Invalid Jumpserver credentials"
+			hint = "Jumpserver rejected the configured credentials. Check the access key pair, the token and its token_type, or the username, password and MFA code."
+		case apiErr.StatusCode == http.StatusForbidden:
+			summary = "Insufficient Jumpserver permissions"
+			hint = "The credentials are valid but the user is not allowed to use the API. Check the user's role and that it is active."
+		case apiErr.StatusCode == http.StatusNotFound:
+			summary = "Jumpserver API not found"
+			hint = fmt.Sprintf("%s does not serve the Jumpserver API. Check that base_url points at the Jumpserver root URL.", baseURL)
+		}
+	case errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCert), errors.As(err, &verifyErr):
+		summary = "Jumpserver TLS certificate verification failed"
+		hint = "Trust the issuing CA with ca_cert_file or ca_cert_pem, set tls_server_name if the certificate is issued for another name, or, for testing only, set skip_tls_verify."
+	case errors.As(err, &recordErr):
+		summary = "Jumpserver TLS handshake failed"
+		hint = "The server did not answer with TLS. Check the scheme of base_url."
+	case errors.As(err, &dnsErr):
+		summary = "Jumpserver host not found"
+		hint = fmt.Sprintf("The host of %s could not be resolved. Check base_url.", baseURL)
+	case errors.As(err, &opErr):
+		summary = "Jumpserver is unreachable"
+		hint = fmt.Sprintf("Could not connect to %s. Check base_url, proxy_url and that Jumpserver is running.", baseURL)
+	case strings.Contains(err.Error(), "decoding"):
+		summary = "Unexpected response from Jumpserver"
+		hint = fmt.Sprintf("%s did not answer with JSON. Check that base_url points at the Jumpserver root URL.", baseURL)
+	}
+
+	detail := err.Error()
+	if hint != "" {
+		detail = hint + "\n\n" + detail
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   detail,
+	}}
+}
@@ -0,0 +1,63 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDomain() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainRead,
+
+		Schema: map[string]*schema.Schema{
+			"org_id": dataSourceOrgIDSchema(),
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "name"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"labels": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	if v, ok := d.GetOk("name"); ok {
+		filters["name"] = v.(string)
+	}
+
+	domain, err := lookupObject(ctx, api, "/api/v1/assets/domains/", "domain", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(domain["id"].(string))
+	d.Set("name", domain["name"])
+	d.Set("comment", domain["comment"])
+	if labels, ok := domain["labels"].([]interface{}); ok {
+		d.Set("labels", flattenLabels(labels))
+	}
+	setOrgID(d, api, domain)
+
+	return diags
+}
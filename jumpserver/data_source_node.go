@@ -0,0 +1,71 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceNode() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNodeRead,
+
+		Schema: map[string]*schema.Schema{
+			"org_id": dataSourceOrgIDSchema(),
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "name", "full_value"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"full_value": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"assets_amount": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceNodeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	if v, ok := d.GetOk("name"); ok {
+		filters["value"] = v.(string)
+	}
+	if v, ok := d.GetOk("full_value"); ok {
+		filters["full_value"] = v.(string)
+	}
+
+	node, err := lookupObject(ctx, api, "/api/v1/assets/nodes/", "node", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(node["id"].(string))
+	d.Set("name", node["value"])
+	d.Set("full_value", node["full_value"])
+	d.Set("key", node["key"])
+	if amount, ok := node["assets_amount"].(float64); ok {
+		d.Set("assets_amount", int(amount))
+	}
+	setOrgID(d, api, node)
+
+	return diags
+}
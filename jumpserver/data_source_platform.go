@@ -0,0 +1,84 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePlatform() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePlatformRead,
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "name"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"category": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"charset": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"internal": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourcePlatformRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	if v, ok := d.GetOk("name"); ok {
+		filters["name"] = v.(string)
+	}
+
+	platform, err := lookupObject(ctx, c.Client, "/api/v1/assets/platforms/", "platform", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// category and type are choice objects, so they are matched here rather
+	// than through the generic field filters.
+	for _, field := range []string{"category", "type"} {
+		if v, ok := d.GetOk(field); ok && flattenChoice(platform[field]) != v.(string) {
+			return diag.Errorf("platform '%v' has %s '%s', not '%s'", platform["name"], field, flattenChoice(platform[field]), v)
+		}
+	}
+
+	platformID, _ := flattenRef(platform["id"])
+	d.SetId(platformID)
+	d.Set("name", platform["name"])
+	d.Set("category", flattenChoice(platform["category"]))
+	d.Set("type", flattenChoice(platform["type"]))
+	d.Set("charset", flattenChoice(platform["charset"]))
+	d.Set("internal", platform["internal"])
+	d.Set("comment", platform["comment"])
+
+	return diags
+}
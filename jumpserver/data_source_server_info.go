@@ -0,0 +1,41 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceServerInfo() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServerInfoRead,
+
+		Schema: map[string]*schema.Schema{
+			"base_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"server_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"major_version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceServerInfoRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	var diags diag.Diagnostics
+
+	d.SetId(c.Client.BaseURL())
+	d.Set("base_url", c.Client.BaseURL())
+	d.Set("server_version", c.ServerVersion.String())
+	d.Set("major_version", c.ServerVersion.major)
+
+	return diags
+}
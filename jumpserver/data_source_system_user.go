@@ -0,0 +1,73 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceSystemUser() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSystemUserRead,
+
+		Schema: map[string]*schema.Schema{
+			"org_id": dataSourceOrgIDSchema(),
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "name", "username"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"protocol": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"login_mode": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceSystemUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	for _, field := range []string{"name", "username"} {
+		if v, ok := d.GetOk(field); ok {
+			filters[field] = v.(string)
+		}
+	}
+
+	systemUser, err := lookupObject(ctx, api, "/api/v1/assets/system-users/", "system user", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(systemUser["id"].(string))
+	d.Set("name", systemUser["name"])
+	d.Set("username", systemUser["username"])
+	d.Set("type", flattenChoice(systemUser["type"]))
+	d.Set("protocol", flattenChoice(systemUser["protocol"]))
+	d.Set("login_mode", flattenChoice(systemUser["login_mode"]))
+	setOrgID(d, api, systemUser)
+
+	return diags
+}
@@ -0,0 +1,85 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceUser() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUserRead,
+
+		Schema: map[string]*schema.Schema{
+			"org_id": dataSourceOrgIDSchema(),
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "username", "name", "email"},
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"is_active": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"system_roles": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"group_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	for _, field := range []string{"username", "name", "email"} {
+		if v, ok := d.GetOk(field); ok {
+			filters[field] = v.(string)
+		}
+	}
+
+	user, err := lookupObject(ctx, api, "/api/v1/users/users/", "user", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(user["id"].(string))
+	d.Set("username", user["username"])
+	d.Set("name", user["name"])
+	d.Set("email", user["email"])
+	d.Set("is_active", user["is_active"])
+	if roles, ok := user["system_roles"].([]interface{}); ok {
+		d.Set("system_roles", flattenRefIDs(roles))
+	}
+	if groups, ok := user["groups"].([]interface{}); ok {
+		d.Set("group_ids", flattenRefIDs(groups))
+	}
+	setOrgID(d, api, user)
+
+	return diags
+}
@@ -0,0 +1,63 @@
+package jumpserver
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceUserGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUserGroupRead,
+
+		Schema: map[string]*schema.Schema{
+			"org_id": dataSourceOrgIDSchema(),
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"id", "name"},
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"user_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceUserGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	filters := map[string]string{}
+	if v, ok := d.GetOk("name"); ok {
+		filters["name"] = v.(string)
+	}
+
+	group, err := lookupObject(ctx, api, "/api/v1/users/groups/", "user group", d.Get("id").(string), filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(group["id"].(string))
+	d.Set("name", group["name"])
+	d.Set("comment", group["comment"])
+	if users, ok := group["users"].([]interface{}); ok {
+		d.Set("user_ids", flattenRefIDs(users))
+	}
+	setOrgID(d, api, group)
+
+	return diags
+}
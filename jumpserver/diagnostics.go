@@ -0,0 +1,64 @@
+package jumpserver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// apiDiagnostics converts an error returned by the API client into
+// diagnostics. Validation errors reported by JumpServer for a specific field
+// become one diagnostic each, pointing at the matching schema attribute.
+// attrs maps API field names to the schema attributes they are set from
+// where the names differ (e.g. "domain" -> "domain_name"), so validation
+// errors point at the right argument. Resources keep this map next to their
+// schema as <resource>APIAttributes.
+func apiDiagnostics(err error, summary string, attrs map[string]string) diag.Diagnostics {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.FieldErrors) == 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   err.Error(),
+		}}
+	}
+
+	var diags diag.Diagnostics
+	if apiErr.Detail != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   apiErr.Detail,
+		})
+	}
+	for _, fe := range apiErr.FieldErrors {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        fmt.Sprintf("%s (HTTP %d from %s %s)", fe.Message, apiErr.StatusCode, apiErr.Method, apiErr.Path),
+			AttributePath: attributePath(fe.Field, attrs),
+		})
+	}
+	return diags
+}
+
+func attributePath(field []interface{}, attrs map[string]string) cty.Path {
+	var path cty.Path
+	for i, step := range field {
+		switch s := step.(type) {
+		case string:
+			if i == 0 {
+				if name, ok := attrs[s]; ok {
+					s = name
+				}
+			}
+			path = path.GetAttr(s)
+		case int:
+			path = path.IndexInt(s)
+		}
+	}
+	return path
+}
@@ -0,0 +1,38 @@
+package jumpserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importStateWithOrg imports a resource by its ID. The ID may be prefixed
+// with an organization ID or name, as in "<org>/<id>", to import a resource
+// living outside the provider organization.
+func importStateWithOrg(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*Config)
+
+	org, id, found := strings.Cut(d.Id(), "/")
+	if !found {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	orgID := org
+	if !isUUID(org) {
+		var err error
+		orgID, err = findOrgIDByName(ctx, c.Client, org)
+		if err != nil {
+			return nil, err
+		}
+	}
+	d.Set("org_id", orgID)
+	d.SetId(id)
+	return []*schema.ResourceData{d}, nil
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.ParseUUID(s)
+	return err == nil
+}
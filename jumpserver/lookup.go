@@ -0,0 +1,118 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+)
+
+// lookupObject fetches a single object of the collection at listPath. With
+// an id the object is read directly; otherwise the collection is listed with
+// filters as query parameters and exactly one object whose fields equal all
+// filters must be found. kind names the object in error messages.
+func lookupObject(ctx context.Context, api *client.Client, listPath, kind, id string, filters map[string]string) (map[string]interface{}, error) {
+	if id != "" {
+		var object map[string]interface{}
+		if err := api.Get(ctx, listPath+url.PathEscape(id)+"/", &object); err != nil {
+			if client.IsNotFound(err) {
+				return nil, fmt.Errorf("%s with id '%s' not found in JumpServer", kind, id)
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+		}
+		return object, nil
+	}
+
+	query := url.Values{}
+	for field, value := range filters {
+		query.Set(field, value)
+	}
+
+	var matches []map[string]interface{}
+	err := api.List(ctx, listPath, query, func(object map[string]interface{}) bool {
+		if matchesFilters(object, filters) {
+			matches = append(matches, object)
+		}
+		return len(matches) < 2
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %ss: %w", kind, err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no %s matching %s found in JumpServer", kind, describeFilters(filters))
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("found %d %ss matching %s, narrow the lookup", len(matches), kind, describeFilters(filters))
+	}
+}
+
+// findFirst returns the first object of the collection at listPath, listed
+// with query as server-side filters, for which match returns true. It
+// returns nil when no object matches.
+func findFirst(ctx context.Context, api *client.Client, listPath string, query url.Values, match func(map[string]interface{}) bool) (map[string]interface{}, error) {
+	var found map[string]interface{}
+	err := api.List(ctx, listPath, query, func(object map[string]interface{}) bool {
+		if match(object) {
+			found = object
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// nameMatcher matches objects whose field equals name, ignoring case.
+func nameMatcher(field, name string) func(map[string]interface{}) bool {
+	return func(object map[string]interface{}) bool {
+		value, ok := object[field].(string)
+		return ok && strings.EqualFold(value, name)
+	}
+}
+
+func matchesFilters(object map[string]interface{}, filters map[string]string) bool {
+	for field, value := range filters {
+		if fmt.Sprint(object[field]) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func describeFilters(filters map[string]string) string {
+	var parts []string
+	for field, value := range filters {
+		parts = append(parts, fmt.Sprintf("%s=%q", field, value))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// flattenChoice reads a choice field returned either as a bare value or as
+// {"value": ..., "label": ...}.
+func flattenChoice(v interface{}) string {
+	switch choice := v.(type) {
+	case string:
+		return choice
+	case map[string]interface{}:
+		value, _ := choice["value"].(string)
+		return value
+	}
+	return ""
+}
+
+// flattenRefIDs returns the IDs of a list of related objects.
+func flattenRefIDs(refs []interface{}) []string {
+	ids := []string{}
+	for _, ref := range refs {
+		if id, _ := flattenRef(ref); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
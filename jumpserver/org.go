@@ -0,0 +1,69 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// orgIDSchema is the org_id attribute shared by every resource living in a
+// JumpServer organization. It defaults to the provider organization and is
+// kept in state so later operations target the same tenant.
+func orgIDSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		ForceNew:    true,
+		Description: "ID of the organization the resource belongs to. Defaults to the provider organization.",
+	}
+}
+
+// dataSourceOrgIDSchema is the org_id argument of data sources, selecting
+// the organization the lookup is made in.
+func dataSourceOrgIDSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "ID of the organization to look the object up in. Defaults to the provider organization.",
+	}
+}
+
+// orgClient returns the API client scoped to the organization of the
+// resource: its org_id when known, otherwise the provider organization.
+func orgClient(c *Config, d *schema.ResourceData) *client.Client {
+	if v, ok := d.GetOk("org_id"); ok {
+		return c.Client.WithOrg(v.(string))
+	}
+	return c.Client
+}
+
+// setOrgID records the organization of a resource in state, preferring the
+// org_id reported by JumpServer over the one the request was made in.
+func setOrgID(d *schema.ResourceData, api *client.Client, result map[string]interface{}) {
+	if orgID, ok := result["org_id"].(string); ok && orgID != "" {
+		d.Set("org_id", orgID)
+		return
+	}
+	if api.OrgID() != "" {
+		d.Set("org_id", api.OrgID())
+	}
+}
+
+func findOrgIDByName(ctx context.Context, api *client.Client, orgName string) (string, error) {
+	org, err := findFirst(ctx, api, "/api/v1/orgs/orgs/", url.Values{"search": {orgName}}, nameMatcher("name", orgName))
+	if err != nil {
+		return "", fmt.Errorf("failed to list organizations: %w", err)
+	}
+	if org == nil {
+		return "", fmt.Errorf("organization '%s' not found in JumpServer", orgName)
+	}
+	if id, ok := org["id"].(string); ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("organization '%s' found but has no 'id'", orgName)
+}
@@ -0,0 +1,159 @@
+package jumpserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultProfileName is the profile used when config_file is set without
+// profile.
+const defaultProfileName = "default"
+
+// profileKeys are the settings a profile may hold.
+var profileKeys = map[string]bool{
+	"base_url":        true,
+	"username":        true,
+	"password":        true,
+	"totp_secret":     true,
+	"access_key":      true,
+	"secret_key":      true,
+	"token":           true,
+	"token_type":      true,
+	"org_id":          true,
+	"org_name":        true,
+	"ca_cert_file":    true,
+	"skip_tls_verify": true,
+}
+
+// profile holds the settings of one named JumpServer endpoint of a profile
+// file.
+type profile map[string]string
+
+// defaultConfigFile returns the profile file read when only profile is set.
+func defaultConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".jumpserver", "config")
+}
+
+// loadProfile reads the named profile from the INI file at path. A relative
+// ca_cert_file is resolved against the directory of the file.
+func loadProfile(path, name string) (profile, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles, err := parseProfiles(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%s: profile %q not found, available profiles: %s", path, name, strings.Join(names, ", "))
+	}
+
+	if ca := p["ca_cert_file"]; ca != "" && !filepath.IsAbs(ca) {
+		p["ca_cert_file"] = filepath.Join(filepath.Dir(path), ca)
+	}
+	return p, nil
+}
+
+// parseProfiles parses an INI document where each section is a profile:
+//
+//	[staging]
+//	base_url   = https://jumpserver.staging.example.com
+//	access_key = XXXXXXX
+//	secret_key = YYYYYYY
+//
+// Lines starting with # or ; are comments. Values may be quoted.
+func parseProfiles(r io.Reader) (map[string]profile, error) {
+	profiles := map[string]profile{}
+	var current profile
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", line)
+			}
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty profile name", line)
+			}
+			if _, ok := profiles[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", line, name)
+			}
+			current = profile{}
+			profiles[name] = current
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: setting outside of a [profile] section", line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !profileKeys[key] {
+			return nil, fmt.Errorf("line %d: unknown setting %q", line, key)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		current[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// bool reports whether the setting key holds a true value.
+func (p profile) bool(key string) bool {
+	v, _ := strconv.ParseBool(p[key])
+	return v
+}
+
+// org returns the organization of the profile unless orgID or orgName, set
+// in the configuration or the environment, already select one. org_id and
+// org_name are one setting, so the profile never adds one to the other.
+func (p profile) org(orgID, orgName string) (string, string) {
+	if orgID != "" || orgName != "" {
+		return orgID, orgName
+	}
+	return p["org_id"], p["org_name"]
+}
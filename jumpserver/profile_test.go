@@ -0,0 +1,33 @@
+package jumpserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfileOrg(t *testing.T) {
+	profiles, err := parseProfiles(strings.NewReader(`
+[staging]
+base_url = https://jumpserver.staging.example.com
+org_id   = 00000000-0000-0000-0000-000000000002
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := profiles["staging"]
+
+	for _, tc := range []struct {
+		name                string
+		orgID, orgName      string
+		wantID, wantOrgName string
+	}{
+		{"profile only", "", "", "00000000-0000-0000-0000-000000000002", ""},
+		{"org_id configured", "00000000-0000-0000-0000-000000000001", "", "00000000-0000-0000-0000-000000000001", ""},
+		{"org_name configured", "", "Finance", "", "Finance"},
+	} {
+		gotID, gotName := p.org(tc.orgID, tc.orgName)
+		if gotID != tc.wantID || gotName != tc.wantOrgName {
+			t.Errorf("%s: got org_id %q and org_name %q, want %q and %q", tc.name, gotID, gotName, tc.wantID, tc.wantOrgName)
+		}
+	}
+}
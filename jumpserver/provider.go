@@ -1,50 +1,31 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"crypto/tls"
-	"encoding/json"
+	"encoding/pem"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"gopkg.in/twindagger/httpsig.v1"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 type Config struct {
 	BaseURL       string
 	Username      string
 	Password      string
-	Token         string
 	AccessKey     string
 	SecretKey     string
 	SkipTLSVerify bool
-}
+	OrgID         string
+	ServerVersion serverVersion
 
-func (c *Config) NewHTTPClient() *http.Client {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: c.SkipTLSVerify,
-		},
-	}
-	return &http.Client{Transport: transport}
-}
-
-func signReq(r *http.Request, accessKey string, secretKey string) error {
-	headers := []string{"(request-target)", "date"}
-	gmtFmt := "Mon, 02 Jan 2006 15:04:05 GMT"
-	r.Header.Add("Date", time.Now().Format(gmtFmt))
-	r.Header.Add("Accept", "application/json")
-
-	signer, err := httpsig.NewRequestSigner(accessKey, secretKey, "hmac-sha256")
-	if err != nil {
-		return err
-	}
-	return signer.SignRequest(r, headers, nil)
+	Client  *client.Client
+	Lookups *lookupCache
 }
 
 func getStringFromEnv(d *schema.ResourceData, key string, envKey string) string {
@@ -62,14 +43,49 @@ func getBoolFromEnv(d *schema.ResourceData, key string, envKey string) bool {
 	return envVal == "true" || envVal == "1" || envVal == "yes"
 }
 
+// readPEM returns the PEM data given inline in value or, when value is
+// empty, read from the file at path. Either must hold at least one PEM
+// block.
+func readPEM(value, path string) ([]byte, error) {
+	data := []byte(value)
+	if value == "" {
+		if path == "" {
+			return nil, nil
+		}
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if block, _ := pem.Decode(data); block == nil {
+		if value == "" {
+			return nil, fmt.Errorf("%s does not contain a PEM block", path)
+		}
+		return nil, fmt.Errorf("not a PEM block")
+	}
+	return data, nil
+}
+
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"base_url": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_BASE_URL", nil),
-				Description: "Jumpserver Base URL. Can also be set via environment variable JUMPSERVER_BASE_URL.",
+				Description: "Jumpserver Base URL. Can also be set via environment variable JUMPSERVER_BASE_URL or read from a profile.",
+			},
+			"config_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_CONFIG_FILE", nil),
+				Description: "Path to an INI file holding named Jumpserver profiles. Defaults to ~/.jumpserver/config when profile is set. Can also be set via environment variable JUMPSERVER_CONFIG_FILE.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_PROFILE", nil),
+				Description: "Name of the profile of config_file to read. Settings set in the configuration or the environment take precedence over the profile. Defaults to \"default\". Can also be set via environment variable JUMPSERVER_PROFILE.",
 			},
 			"username": {
 				Type:        schema.TypeString,
@@ -84,6 +100,36 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_PASSWORD", nil),
 				Description: "Jumpserver Password. Can also be set via environment variable JUMPSERVER_PASSWORD.",
 			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_TOKEN", nil),
+				Description: "Pre-issued Jumpserver token, e.g. a private token. Can also be set via environment variable JUMPSERVER_TOKEN.",
+			},
+			"token_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("JUMPSERVER_TOKEN_TYPE", client.TokenTypeBearer),
+				ValidateFunc: validation.StringInSlice([]string{client.TokenTypeBearer, client.TokenTypePrivate}, false),
+				Description:  "How token is sent: \"Bearer\" for bearer tokens or \"Token\" for private tokens. Defaults to \"Bearer\". Can also be set via environment variable JUMPSERVER_TOKEN_TYPE.",
+			},
+			"otp_code": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_OTP_CODE", nil),
+				ConflictsWith: []string{"totp_secret"},
+				Description:   "One-time MFA code used to log in with username and password when the account has MFA enabled. Can also be set via environment variable JUMPSERVER_OTP_CODE.",
+			},
+			"totp_secret": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_TOTP_SECRET", nil),
+				ConflictsWith: []string{"otp_code"},
+				Description:   "Base32 TOTP secret of the account, used to generate the MFA code of every login when the account has MFA enabled. Can also be set via environment variable JUMPSERVER_TOTP_SECRET.",
+			},
 			"access_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -104,13 +150,137 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "If true, skip SSL certificate validation (insecure). Can also be set via environment variable JUMPSERVER_SKIP_TLS_VERIFY.",
 			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CA_CERT_FILE", nil),
+				ConflictsWith: []string{"ca_cert_pem"},
+				Description:   "Path to a PEM bundle of CA certificates trusted in addition to the system ones. Can also be set via environment variable JUMPSERVER_CA_CERT_FILE.",
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CA_CERT_PEM", nil),
+				ConflictsWith: []string{"ca_cert_file"},
+				Description:   "PEM-encoded CA certificates trusted in addition to the system ones. Can also be set via environment variable JUMPSERVER_CA_CERT_PEM.",
+			},
+			"client_cert": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CLIENT_CERT", nil),
+				ConflictsWith: []string{"client_cert_file"},
+				Description:   "PEM-encoded client certificate for mutual TLS. Can also be set via environment variable JUMPSERVER_CLIENT_CERT.",
+			},
+			"client_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CLIENT_CERT_FILE", nil),
+				ConflictsWith: []string{"client_cert"},
+				Description:   "Path to the PEM file of the client certificate for mutual TLS. Can also be set via environment variable JUMPSERVER_CLIENT_CERT_FILE.",
+			},
+			"client_key": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CLIENT_KEY", nil),
+				ConflictsWith: []string{"client_key_file"},
+				Description:   "PEM-encoded private key of the client certificate. Can also be set via environment variable JUMPSERVER_CLIENT_KEY.",
+			},
+			"client_key_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_CLIENT_KEY_FILE", nil),
+				ConflictsWith: []string{"client_key"},
+				Description:   "Path to the PEM file of the private key of the client certificate. Can also be set via environment variable JUMPSERVER_CLIENT_KEY_FILE.",
+			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_TLS_SERVER_NAME", nil),
+				Description: "Server name used to verify the Jumpserver certificate, when it differs from the host of base_url. Can also be set via environment variable JUMPSERVER_TLS_SERVER_NAME.",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("JUMPSERVER_PROXY_URL", nil),
+				Description: "URL of the HTTP(S) proxy to reach Jumpserver through. Defaults to the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables. Can also be set via environment variable JUMPSERVER_PROXY_URL.",
+			},
+			"org_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_ORG_ID", nil),
+				ConflictsWith: []string{"org_name"},
+				Description:   "ID of the Jumpserver organization resources are managed in, unless overridden per resource. Can also be set via environment variable JUMPSERVER_ORG_ID.",
+			},
+			"org_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("JUMPSERVER_ORG_NAME", nil),
+				ConflictsWith: []string{"org_id"},
+				Description:   "Name of the Jumpserver organization resources are managed in, resolved to its ID. Can also be set via environment variable JUMPSERVER_ORG_NAME.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Timeout in seconds for a single request to Jumpserver. Set to 0 to disable the timeout.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of times a request is retried after a transient failure (429, 502, 503, 504 or a network error). Set to 0 to disable retries.",
+			},
+			"retry_min_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Minimum time in seconds to wait before retrying a request. Must not exceed retry_max_wait.",
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum time in seconds to wait before retrying a request.",
+			},
+			"max_requests_per_second": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Default:     0,
+				Description: "Maximum number of requests per second sent to Jumpserver. Requests over the limit wait for their turn. 0 means unlimited.",
+			},
+			"max_concurrent_requests": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Maximum number of requests in flight to Jumpserver at any time. 0 means unlimited.",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"jumpserver_host":             resourceHost(),
+			"jumpserver_host":             requireServerVersion("jumpserver_host", assetTypeSupport, resourceHost()),
 			"jumpserver_user":             resourceUser(),
 			"jumpserver_asset":            resourceAsset(),
-			"jumpserver_system_user":      resourceSystemUser(),
+			"jumpserver_system_user":      requireServerVersion("jumpserver_system_user", systemUserSupport, resourceSystemUser()),
 			"jumpserver_asset_permission": resourceAssetPermission(),
+			"jumpserver_node":             resourceNode(),
+			"jumpserver_domain":           resourceDomain(),
+			"jumpserver_gateway":          requireServerVersion("jumpserver_gateway", assetTypeSupport, resourceGateway()),
+			"jumpserver_platform":         requireServerVersion("jumpserver_platform", assetTypeSupport, resourcePlatform()),
+			"jumpserver_account":          requireServerVersion("jumpserver_account", accountSupport, resourceAccount()),
+			"jumpserver_account_template": requireServerVersion("jumpserver_account_template", accountSupport, resourceAccountTemplate()),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"jumpserver_node":        dataSourceNode(),
+			"jumpserver_domain":      dataSourceDomain(),
+			"jumpserver_platform":    dataSourcePlatform(),
+			"jumpserver_user":        dataSourceUser(),
+			"jumpserver_user_group":  dataSourceUserGroup(),
+			"jumpserver_system_user": requireServerVersion("jumpserver_system_user", systemUserSupport, dataSourceSystemUser()),
+			"jumpserver_server_info": dataSourceServerInfo(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -118,71 +288,170 @@ func Provider() *schema.Provider {
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	var token string
 
 	baseURL := getStringFromEnv(d, "base_url", "JUMPSERVER_BASE_URL")
 	username := getStringFromEnv(d, "username", "JUMPSERVER_USERNAME")
 	password := getStringFromEnv(d, "password", "JUMPSERVER_PASSWORD")
+	token := getStringFromEnv(d, "token", "JUMPSERVER_TOKEN")
+	tokenType := getStringFromEnv(d, "token_type", "JUMPSERVER_TOKEN_TYPE")
+	otpCode := getStringFromEnv(d, "otp_code", "JUMPSERVER_OTP_CODE")
+	totpSecret := getStringFromEnv(d, "totp_secret", "JUMPSERVER_TOTP_SECRET")
 	accessKey := getStringFromEnv(d, "access_key", "JUMPSERVER_ACCESS_KEY")
 	secretKey := getStringFromEnv(d, "secret_key", "JUMPSERVER_SECRET_KEY")
 	skipTLS := getBoolFromEnv(d, "skip_tls_verify", "JUMPSERVER_SKIP_TLS_VERIFY")
+	orgID := getStringFromEnv(d, "org_id", "JUMPSERVER_ORG_ID")
+	orgName := getStringFromEnv(d, "org_name", "JUMPSERVER_ORG_NAME")
+	requestTimeout := time.Duration(d.Get("request_timeout").(int)) * time.Second
+	maxRetries := d.Get("max_retries").(int)
+	retryMinWait := time.Duration(d.Get("retry_min_wait").(int)) * time.Second
+	retryMaxWait := time.Duration(d.Get("retry_max_wait").(int)) * time.Second
+	if retryMinWait > retryMaxWait {
+		return nil, diag.Errorf("retry_min_wait (%d) must not be greater than retry_max_wait (%d).", d.Get("retry_min_wait").(int), d.Get("retry_max_wait").(int))
+	}
+	maxRequestsPerSecond := d.Get("max_requests_per_second").(float64)
+	maxConcurrentRequests := d.Get("max_concurrent_requests").(int)
+	caCertPEM := getStringFromEnv(d, "ca_cert_pem", "JUMPSERVER_CA_CERT_PEM")
+	caCertFile := getStringFromEnv(d, "ca_cert_file", "JUMPSERVER_CA_CERT_FILE")
+
+	configFile := getStringFromEnv(d, "config_file", "JUMPSERVER_CONFIG_FILE")
+	profileName := getStringFromEnv(d, "profile", "JUMPSERVER_PROFILE")
+	if configFile != "" || profileName != "" {
+		if configFile == "" {
+			configFile = defaultConfigFile()
+		}
+		if profileName == "" {
+			profileName = defaultProfileName
+		}
+		p, err := loadProfile(configFile, profileName)
+		if err != nil {
+			return nil, diag.Errorf("Failed to read Jumpserver profile %q: %s", profileName, err)
+		}
+
+		if p["org_id"] != "" && p["org_name"] != "" {
+			return nil, diag.Errorf("Jumpserver profile %q sets both org_id and org_name, only one is allowed.", profileName)
+		}
+
+		// Settings from the configuration or the environment win over the profile.
+		fill := func(value *string, key string) {
+			if *value == "" {
+				*value = p[key]
+			}
+		}
+		fill(&baseURL, "base_url")
+		orgID, orgName = p.org(orgID, orgName)
+		if caCertPEM == "" {
+			fill(&caCertFile, "ca_cert_file")
+		}
+		if accessKey == "" && secretKey == "" && username == "" && password == "" && token == "" {
+			token = p["token"]
+			if p["token_type"] != "" {
+				tokenType = p["token_type"]
+			}
+			accessKey, secretKey = p["access_key"], p["secret_key"]
+			username, password = p["username"], p["password"]
+			if otpCode == "" {
+				fill(&totpSecret, "totp_secret")
+			}
+		}
+		if !skipTLS {
+			skipTLS = p.bool("skip_tls_verify")
+		}
+	}
 
 	if baseURL == "" {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Missing base URL",
-			Detail:   "The Jumpserver base URL must be set either in the configuration or via the JUMPSERVER_BASE_URL environment variable.",
+			Detail:   "The Jumpserver base URL must be set in the configuration, via the JUMPSERVER_BASE_URL environment variable or in the selected profile.",
 		})
 		return nil, diags
 	}
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: skipTLS,
-		},
+	diags = append(diags, validateCredentials(credentials{
+		username:   username,
+		password:   password,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		token:      token,
+		tokenType:  tokenType,
+		otpCode:    otpCode,
+		totpSecret: totpSecret,
+	})...)
+	if diags.HasError() {
+		return nil, diags
 	}
-	client := &http.Client{Transport: transport}
 
-	if accessKey == "" || secretKey == "" {
-		var err error
-		token, err = getToken(client, baseURL, username, password)
+	caCert, err := readPEM(caCertPEM, caCertFile)
+	if err != nil {
+		return nil, diag.Errorf("Failed to read CA certificate: %s", err)
+	}
+	clientCert, err := readPEM(getStringFromEnv(d, "client_cert", "JUMPSERVER_CLIENT_CERT"), getStringFromEnv(d, "client_cert_file", "JUMPSERVER_CLIENT_CERT_FILE"))
+	if err != nil {
+		return nil, diag.Errorf("Failed to read client certificate: %s", err)
+	}
+	clientKey, err := readPEM(getStringFromEnv(d, "client_key", "JUMPSERVER_CLIENT_KEY"), getStringFromEnv(d, "client_key_file", "JUMPSERVER_CLIENT_KEY_FILE"))
+	if err != nil {
+		return nil, diag.Errorf("Failed to read client key: %s", err)
+	}
+	if (clientCert == nil) != (clientKey == nil) {
+		return nil, diag.Errorf("A client certificate and its private key must be set together: set both client_cert or client_cert_file and client_key or client_key_file.")
+	}
+
+	apiClient, err := client.New(client.Config{
+		BaseURL:        baseURL,
+		AccessKey:      accessKey,
+		SecretKey:      secretKey,
+		Token:          token,
+		TokenType:      tokenType,
+		SkipTLSVerify:  skipTLS,
+		CACertPEM:      caCert,
+		ClientCertPEM:  clientCert,
+		ClientKeyPEM:   clientKey,
+		TLSServerName:  getStringFromEnv(d, "tls_server_name", "JUMPSERVER_TLS_SERVER_NAME"),
+		ProxyURL:       getStringFromEnv(d, "proxy_url", "JUMPSERVER_PROXY_URL"),
+		RequestTimeout: requestTimeout,
+		MaxRetries:     maxRetries,
+		RetryMinWait:   retryMinWait,
+		RetryMaxWait:   retryMaxWait,
+
+		MaxRequestsPerSecond:  maxRequestsPerSecond,
+		MaxConcurrentRequests: maxConcurrentRequests,
+	})
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	if username != "" {
+		mfa := client.MFA{OTPCode: otpCode, TOTPSecret: totpSecret}
+		if err := apiClient.Login(ctx, username, password, mfa); err != nil {
+			return nil, connectionDiagnostics(baseURL, err)
+		}
+	}
+	if diags := checkConnection(ctx, apiClient); diags.HasError() {
+		return nil, diags
+	}
+
+	if orgID == "" && orgName != "" {
+		orgID, err = findOrgIDByName(ctx, apiClient, orgName)
 		if err != nil {
 			return nil, diag.FromErr(err)
 		}
 	}
+	apiClient = apiClient.WithOrg(orgID)
+
+	version := detectServerVersion(ctx, apiClient)
+	tflog.Info(ctx, "Connected to JumpServer", map[string]interface{}{"server_version": version.String()})
 
 	return &Config{
-		Token:         token,
 		BaseURL:       baseURL,
 		Username:      username,
 		Password:      password,
 		AccessKey:     accessKey,
 		SecretKey:     secretKey,
 		SkipTLSVerify: skipTLS,
+		OrgID:         orgID,
+		ServerVersion: version,
+		Client:        apiClient,
+		Lookups:       newLookupCache(),
 	}, diags
 }
-
-func getToken(client *http.Client, baseURL, username, password string) (string, error) {
-	url := baseURL + "/api/v1/authentication/auth/"
-	credentials := map[string]string{
-		"username": username,
-		"password": password,
-	}
-	jsonValue, _ := json.Marshal(credentials)
-
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
-	}
-
-	if token, ok := result["token"].(string); ok {
-		return token, nil
-	}
-	return "", fmt.Errorf("unable to fetch token from %s", url)
-}
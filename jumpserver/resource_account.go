@@ -0,0 +1,356 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var accountAPIAttributes = map[string]string{
+	"asset": "asset_id",
+}
+
+// accountSecretTypes are the kinds of secret an account can hold.
+var accountSecretTypes = []string{"password", "ssh_key", "token", "access_key"}
+
+func resourceAccount() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAccountCreate,
+		ReadContext:   resourceAccountRead,
+		UpdateContext: resourceAccountUpdate,
+		DeleteContext: resourceAccountDelete,
+		CustomizeDiff: resourceAccountCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
+			"asset_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"secret_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "password",
+				ValidateFunc: validation.StringInSlice(accountSecretTypes, false),
+			},
+			"secret": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				StateFunc:     hashSecret,
+				ConflictsWith: []string{"ssh_key"},
+			},
+			"secret_strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "specific",
+				ValidateFunc: validation.StringInSlice(accountSecretStrategies, false),
+			},
+			"password_rules": passwordRulesSchema(),
+			"ssh_key": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"secret"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"algorithm": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "ed25519",
+							ValidateFunc: validation.StringInSlice([]string{"ed25519", "rsa"}, false),
+						},
+						"rsa_bits": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      4096,
+							ValidateFunc: validation.IntInSlice([]int{2048, 3072, 4096}),
+						},
+					},
+				},
+			},
+			"secret_version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"public_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"secret_fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"privileged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"su_from": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"is_active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"push_now": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourceAccountCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	accountData := expandAccount(d)
+	accountData["asset"] = d.Get("asset_id").(string)
+	secret, err := expandAccountSecret(d, accountData, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var result map[string]interface{}
+	if err := api.Post(ctx, "/api/v1/accounts/accounts/", accountData, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create account", accountAPIAttributes)
+	}
+
+	accountID, ok := result["id"].(string)
+	if !ok {
+		return diag.Errorf("No 'id' field found in account creation response")
+	}
+	d.SetId(accountID)
+	setOrgID(d, api, result)
+	setAccountSecret(d, secret)
+
+	return append(diags, resourceAccountRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourceAccountRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/accounts/accounts/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read account", nil)
+	}
+
+	// The secret is never returned, so the configured one is kept.
+	if assetID, _ := flattenRef(result["asset"]); assetID != "" {
+		d.Set("asset_id", assetID)
+	}
+	d.Set("name", result["name"])
+	d.Set("username", result["username"])
+	d.Set("secret_type", flattenChoice(result["secret_type"]))
+	d.Set("privileged", result["privileged"])
+	d.Set("is_active", result["is_active"])
+	d.Set("comment", result["comment"])
+	suFrom, _ := flattenRef(result["su_from"])
+	d.Set("su_from", suFrom)
+	setOrgID(d, api, result)
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourceAccountUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+
+	// The secret is only sent when it changes, so JumpServer does not
+	// record a secret change on every update.
+	accountData := expandAccount(d)
+	secret, err := expandAccountSecret(d, accountData, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := api.Patch(ctx, fmt.Sprintf("/api/v1/accounts/accounts/%s/", d.Id()), accountData, nil); err != nil {
+		// Keep the previous state, including secret_version, so a rotation
+		// that did not go through is retried by the next apply.
+		d.Partial(true)
+		return apiDiagnostics(err, "Failed to update account", accountAPIAttributes)
+	}
+	setAccountSecret(d, secret)
+
+	return resourceAccountRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourceAccountDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/accounts/accounts/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete account", nil)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+// resourceAccountCustomizeDiff checks that the secret options fit the
+// secret type, and marks the public key and fingerprint as unknown when the
+// secret is going to change.
+func resourceAccountCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	secretType := d.Get("secret_type").(string)
+	generateKey := len(d.Get("ssh_key").([]interface{})) > 0
+	random := d.Get("secret_strategy").(string) == "random"
+
+	switch {
+	case generateKey && secretType != "ssh_key":
+		return fmt.Errorf("ssh_key requires secret_type \"ssh_key\", got %q", secretType)
+	case generateKey && random:
+		return fmt.Errorf("ssh_key generates the secret in the provider and cannot be used with secret_strategy \"random\"")
+	case random && secretType != "password":
+		return fmt.Errorf("secret_strategy \"random\" only generates passwords, got secret_type %q", secretType)
+	case random && d.Get("secret").(string) != "":
+		return fmt.Errorf("secret cannot be set with secret_strategy \"random\"")
+	}
+
+	if d.Id() == "" || d.HasChanges("secret", "secret_version", "ssh_key", "secret_strategy") {
+		if err := d.SetNewComputed("public_key"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("secret_fingerprint")
+	}
+	return nil
+}
+
+// accountSecret describes a secret sent to JumpServer. Its public key and
+// fingerprint are only saved once JumpServer accepted the secret.
+type accountSecret struct {
+	publicKey   string
+	fingerprint string
+}
+
+// expandAccountSecret adds the secret to the account payload when it is
+// created or has to change, and returns it, or nil when the secret is left
+// unchanged. A secret is given by the user, generated by JumpServer with the
+// random strategy, or generated here as an SSH key pair of which only the
+// private key is sent. Generated secrets are only rotated when
+// secret_version is bumped.
+func expandAccountSecret(d *schema.ResourceData, accountData map[string]interface{}, create bool) (*accountSecret, error) {
+	rotate := create || d.HasChange("secret_version")
+
+	if v, ok := d.GetOk("ssh_key"); ok {
+		if !rotate && !d.HasChange("ssh_key") {
+			return nil, nil
+		}
+		options := v.([]interface{})[0].(map[string]interface{})
+		key, err := generateSSHKey(options["algorithm"].(string), options["rsa_bits"].(int))
+		if err != nil {
+			return nil, fmt.Errorf("generating SSH key: %w", err)
+		}
+		accountData["secret"] = key.privateKeyPEM
+		return &accountSecret{publicKey: key.publicKey, fingerprint: key.fingerprint}, nil
+	}
+
+	if d.Get("secret_strategy").(string) == "random" {
+		// The rules only apply when a password is generated, so changing
+		// them alone does not rotate it.
+		if !rotate && !d.HasChange("secret_strategy") {
+			return nil, nil
+		}
+		accountData["secret_strategy"] = "random"
+		if rules := expandPasswordRules(d.Get("password_rules").([]interface{})); rules != nil {
+			accountData["password_rules"] = rules
+		}
+		return &accountSecret{}, nil
+	}
+
+	if !create && !d.HasChange("secret") {
+		if !d.HasChange("secret_version") {
+			return nil, nil
+		}
+		// A given secret is not rotated by secret_version.
+		fingerprint, _ := d.GetChange("secret_fingerprint")
+		return &accountSecret{fingerprint: fingerprint.(string)}, nil
+	}
+	// d.Get returns the configured secret here, not the hash kept in state.
+	secret := d.Get("secret").(string)
+	if secret != "" {
+		accountData["secret"] = secret
+	}
+	return &accountSecret{fingerprint: hashSecret(secret)}, nil
+}
+
+// setAccountSecret saves the public key and fingerprint of the secret
+// accepted by JumpServer.
+func setAccountSecret(d *schema.ResourceData, secret *accountSecret) {
+	if secret == nil {
+		return
+	}
+	d.Set("public_key", secret.publicKey)
+	d.Set("secret_fingerprint", secret.fingerprint)
+}
+
+// expandAccount builds the account payload shared by create and update.
+// push_now makes JumpServer push the account to the asset right away.
+func expandAccount(d *schema.ResourceData) map[string]interface{} {
+	accountData := map[string]interface{}{
+		"username":    d.Get("username").(string),
+		"secret_type": d.Get("secret_type").(string),
+		"privileged":  d.Get("privileged").(bool),
+		"is_active":   d.Get("is_active").(bool),
+		"push_now":    d.Get("push_now").(bool),
+		"comment":     d.Get("comment").(string),
+		"su_from":     nil,
+	}
+	if v, ok := d.GetOk("name"); ok {
+		accountData["name"] = v.(string)
+	} else {
+		accountData["name"] = d.Get("username").(string)
+	}
+	if v, ok := d.GetOk("su_from"); ok {
+		accountData["su_from"] = v.(string)
+	}
+	return accountData
+}
@@ -0,0 +1,270 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// accountSecretStrategies are how the secret of an account is obtained:
+// given by the user, or generated by JumpServer.
+var accountSecretStrategies = []string{"specific", "random"}
+
+func resourceAccountTemplate() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAccountTemplateCreate,
+		ReadContext:   resourceAccountTemplateRead,
+		UpdateContext: resourceAccountTemplateUpdate,
+		DeleteContext: resourceAccountTemplateDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"secret_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "password",
+				ValidateFunc: validation.StringInSlice(accountSecretTypes, false),
+			},
+			"secret_strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "specific",
+				ValidateFunc: validation.StringInSlice(accountSecretStrategies, false),
+			},
+			"secret": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				StateFunc: hashSecret,
+			},
+			"password_rules": passwordRulesSchema(),
+			"auto_push": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"privileged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"su_from": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"is_active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// passwordRulesSchema is the password_rules block describing the passwords
+// JumpServer generates with the random secret strategy.
+func passwordRulesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"length": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      16,
+					ValidateFunc: validation.IntBetween(8, 64),
+				},
+				"lowercase": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"uppercase": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"digit": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"symbol": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+			},
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourceAccountTemplateCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	templateData := expandAccountTemplate(d)
+	if v, ok := d.GetOk("secret"); ok {
+		templateData["secret"] = v.(string)
+	}
+
+	var result map[string]interface{}
+	if err := api.Post(ctx, "/api/v1/accounts/account-templates/", templateData, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create account template", nil)
+	}
+
+	templateID, ok := result["id"].(string)
+	if !ok {
+		return diag.Errorf("No 'id' field found in account template creation response")
+	}
+	d.SetId(templateID)
+	setOrgID(d, api, result)
+
+	return append(diags, resourceAccountTemplateRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourceAccountTemplateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/accounts/account-templates/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read account template", nil)
+	}
+
+	// The secret is never returned, so the configured one is kept.
+	d.Set("name", result["name"])
+	d.Set("username", result["username"])
+	d.Set("secret_type", flattenChoice(result["secret_type"]))
+	if strategy := flattenChoice(result["secret_strategy"]); strategy != "" {
+		d.Set("secret_strategy", strategy)
+	}
+	if rules, ok := result["password_rules"].(map[string]interface{}); ok && len(d.Get("password_rules").([]interface{})) > 0 {
+		d.Set("password_rules", flattenPasswordRules(rules))
+	}
+	d.Set("auto_push", result["auto_push"])
+	d.Set("privileged", result["privileged"])
+	d.Set("is_active", result["is_active"])
+	d.Set("comment", result["comment"])
+	suFrom, _ := flattenRef(result["su_from"])
+	d.Set("su_from", suFrom)
+	setOrgID(d, api, result)
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourceAccountTemplateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+
+	templateData := expandAccountTemplate(d)
+	if d.HasChange("secret") {
+		templateData["secret"] = d.Get("secret").(string)
+	}
+
+	if err := api.Patch(ctx, fmt.Sprintf("/api/v1/accounts/account-templates/%s/", d.Id()), templateData, nil); err != nil {
+		return apiDiagnostics(err, "Failed to update account template", nil)
+	}
+
+	return resourceAccountTemplateRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourceAccountTemplateDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/accounts/account-templates/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete account template", nil)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+func expandAccountTemplate(d *schema.ResourceData) map[string]interface{} {
+	templateData := map[string]interface{}{
+		"name":            d.Get("name").(string),
+		"username":        d.Get("username").(string),
+		"secret_type":     d.Get("secret_type").(string),
+		"secret_strategy": d.Get("secret_strategy").(string),
+		"auto_push":       d.Get("auto_push").(bool),
+		"privileged":      d.Get("privileged").(bool),
+		"is_active":       d.Get("is_active").(bool),
+		"comment":         d.Get("comment").(string),
+		"su_from":         nil,
+	}
+	if v, ok := d.GetOk("su_from"); ok {
+		templateData["su_from"] = v.(string)
+	}
+	if rules := expandPasswordRules(d.Get("password_rules").([]interface{})); rules != nil {
+		templateData["password_rules"] = rules
+	}
+	return templateData
+}
+
+func expandPasswordRules(list []interface{}) map[string]interface{} {
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	m := list[0].(map[string]interface{})
+	return map[string]interface{}{
+		"length":    m["length"].(int),
+		"lowercase": m["lowercase"].(bool),
+		"uppercase": m["uppercase"].(bool),
+		"digit":     m["digit"].(bool),
+		"symbol":    m["symbol"].(bool),
+	}
+}
+
+func flattenPasswordRules(rules map[string]interface{}) []interface{} {
+	return []interface{}{map[string]interface{}{
+		"length":    rules["length"],
+		"lowercase": rules["lowercase"],
+		"uppercase": rules["uppercase"],
+		"digit":     rules["digit"],
+		"symbol":    rules["symbol"],
+	}}
+}
@@ -1,24 +1,44 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// assetV3APIAttributes maps the field names of the JumpServer v3 asset API to
+// the attributes they are configured with. The v2 API uses the attribute
+// names themselves.
+var assetV3APIAttributes = map[string]string{
+	"name":    "hostname",
+	"address": "ip",
+}
+
 func resourceAsset() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceAssetCreate,
 		ReadContext:   resourceAssetRead,
 		UpdateContext: resourceAssetUpdate,
 		DeleteContext: resourceAssetDelete,
+		CustomizeDiff: resourceAssetCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
 			"hostname": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -47,51 +67,22 @@ func resourceAsset() *schema.Resource {
 
 func resourceAssetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 	var diags diag.Diagnostics
 
-	asset := map[string]interface{}{
-		"hostname":      d.Get("hostname").(string),
-		"ip":            d.Get("ip").(string),
-		"platform":      d.Get("platform").(string),
-		"protocols":     d.Get("protocols").([]interface{}),
-		"nodes_display": d.Get("nodes_display").([]interface{}),
-	}
-
-	url := c.BaseURL + "/api/v1/assets/assets/"
-	jsonValue, _ := json.Marshal(asset)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
+	asset, err := expandAsset(ctx, c, api, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 201 Created status code
-	if resp.StatusCode != http.StatusCreated {
-		return diag.Errorf("Error creating asset: %s", resp.Status)
-	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Post(ctx, "/api/v1/assets/assets/", asset, &result); err != nil {
+		return apiDiagnostics(err, "Error creating asset", assetAPIAttributes(c.ServerVersion))
 	}
 
 	if id, ok := result["id"].(string); ok {
 		d.SetId(id)
+		setOrgID(d, api, result)
 	} else {
 		return diag.Errorf("Error retrieving asset ID after creation, response: %v", result)
 	}
@@ -103,91 +94,42 @@ func resourceAssetCreate(ctx context.Context, d *schema.ResourceData, m interfac
 
 func resourceAssetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/assets/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 200 OK status code
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error fetching asset: %s", resp.Status)
-	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/assets/%s/", id), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return apiDiagnostics(err, "Error fetching asset", nil)
 	}
 
 	// Update resource data with fetched values
-	d.Set("hostname", result["hostname"].(string))
-	d.Set("ip", result["ip"].(string))
-	d.Set("platform", result["platform"].(string))
-	d.Set("protocols", result["protocols"].([]interface{}))
-	d.Set("nodes_display", result["nodes_display"].([]interface{}))
+	flattenAsset(d, result, c.ServerVersion)
+	setOrgID(d, api, result)
 
 	return diags
 }
 
 func resourceAssetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 
 	var diags diag.Diagnostics
 
-	asset := map[string]interface{}{
-		"hostname":      d.Get("hostname").(string),
-		"ip":            d.Get("ip").(string),
-		"platform":      d.Get("platform").(string),
-		"protocols":     d.Get("protocols").([]interface{}),
-		"nodes_display": d.Get("nodes_display").([]interface{}),
-	}
-
-	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/assets/%s/", c.BaseURL, id)
-	jsonValue, _ := json.Marshal(asset)
-
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonValue))
+	asset, err := expandAsset(ctx, c, api, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 200 OK status code
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error updating asset: %s", resp.Status)
+	id := d.Id()
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/assets/assets/%s/", id), asset, nil); err != nil {
+		return apiDiagnostics(err, "Error updating asset", assetAPIAttributes(c.ServerVersion))
 	}
 
 	resourceAssetRead(ctx, d, m)
@@ -196,37 +138,99 @@ func resourceAssetUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceAssetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/assets/%s/", c.BaseURL, id)
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/assets/%s/", id)); err != nil {
+		return apiDiagnostics(err, "Error deleting asset", nil)
+	}
 
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
+	d.SetId("") // Mark resource as destroyed
+	return diags
+}
+
+// resourceAssetCustomizeDiff rejects nodes_display on JumpServer v3, whose
+// asset API no longer accepts it, rather than dropping it from the payload.
+func resourceAssetCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c, ok := m.(*Config)
+	if !ok || !c.ServerVersion.known() || c.ServerVersion.major < 3 {
+		return nil
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
+	if len(d.Get("nodes_display").([]interface{})) > 0 {
+		return fmt.Errorf("nodes_display is not supported by JumpServer v%s: use jumpserver_host to place hosts in nodes", c.ServerVersion)
 	}
+	return nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
+func assetAPIAttributes(v serverVersion) map[string]string {
+	if !v.known() || v.major < 3 {
+		return nil
 	}
-	defer resp.Body.Close()
+	return assetV3APIAttributes
+}
 
-	// Check for 204 No Content status code
-	if resp.StatusCode != http.StatusNoContent {
-		return diag.Errorf("Error deleting asset: %s", resp.Status)
+// expandAsset builds the asset payload in the shape the server expects.
+// JumpServer v3 renamed hostname and ip to name and address, takes the
+// platform by ID and protocols as objects rather than "name/port" strings,
+// and no longer accepts nodes_display.
+func expandAsset(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData) (map[string]interface{}, error) {
+	if v := c.ServerVersion; !v.known() || v.major < 3 {
+		return map[string]interface{}{
+			"hostname":      d.Get("hostname").(string),
+			"ip":            d.Get("ip").(string),
+			"platform":      d.Get("platform").(string),
+			"protocols":     d.Get("protocols").([]interface{}),
+			"nodes_display": d.Get("nodes_display").([]interface{}),
+		}, nil
+	}
+
+	platform, err := findPlatformID(ctx, c, api, d.Get("platform").(string))
+	if err != nil {
+		return nil, err
 	}
 
-	d.SetId("") // Mark resource as destroyed
-	return diags
+	protocols := make([]interface{}, 0)
+	for _, p := range d.Get("protocols").([]interface{}) {
+		name, port, _ := strings.Cut(p.(string), "/")
+		protocol := map[string]interface{}{"name": name}
+		if n, err := strconv.Atoi(port); err == nil {
+			protocol["port"] = n
+		}
+		protocols = append(protocols, protocol)
+	}
+	return map[string]interface{}{
+		"name":      d.Get("hostname").(string),
+		"address":   d.Get("ip").(string),
+		"platform":  platform,
+		"protocols": protocols,
+	}, nil
+}
+
+// flattenAsset sets the asset attributes from either API generation.
+func flattenAsset(d *schema.ResourceData, result map[string]interface{}, v serverVersion) {
+	if !v.known() || v.major < 3 {
+		d.Set("hostname", result["hostname"])
+		d.Set("ip", result["ip"])
+		d.Set("platform", result["platform"])
+		d.Set("protocols", result["protocols"])
+		d.Set("nodes_display", result["nodes_display"])
+		return
+	}
+
+	d.Set("hostname", result["name"])
+	d.Set("ip", result["address"])
+	if platform, ok := result["platform"].(map[string]interface{}); ok {
+		d.Set("platform", platform["name"])
+	}
+	protocols := make([]string, 0)
+	if items, ok := result["protocols"].([]interface{}); ok {
+		for _, item := range items {
+			if p, ok := item.(map[string]interface{}); ok {
+				protocols = append(protocols, fmt.Sprintf("%v/%v", p["name"], p["port"]))
+			}
+		}
+	}
+	d.Set("protocols", protocols)
 }
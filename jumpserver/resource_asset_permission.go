@@ -1,12 +1,11 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -17,8 +16,19 @@ func resourceAssetPermission() *schema.Resource {
 		ReadContext:   resourceAssetPermissionRead,
 		UpdateContext: resourceAssetPermissionUpdate,
 		DeleteContext: resourceAssetPermissionDelete,
+		CustomizeDiff: resourceAssetPermissionCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -47,53 +57,20 @@ func resourceAssetPermission() *schema.Resource {
 }
 
 func resourceAssetPermissionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
-	permission := map[string]interface{}{
-		"name":                 d.Get("name").(string),
-		"is_active":            d.Get("is_active").(bool),
-		"users_display":        d.Get("users_display").([]interface{}),
-		"assets_display":       d.Get("assets_display").([]interface{}),
-		"system_users_display": d.Get("system_users_display").([]interface{}),
-	}
-
-	url := c.BaseURL + "/api/v1/perms/asset-permissions/"
-	jsonValue, _ := json.Marshal(permission)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 201 Created status code
-	if resp.StatusCode != http.StatusCreated {
-		return diag.Errorf("Error creating asset permission: %s", resp.Status)
-	}
+	permission := expandAssetPermission(d, m.(*Config).ServerVersion)
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Post(ctx, "/api/v1/perms/asset-permissions/", permission, &result); err != nil {
+		return apiDiagnostics(err, "Error creating asset permission", nil)
 	}
 
 	if id, ok := result["id"].(string); ok {
 		d.SetId(id)
+		setOrgID(d, api, result)
 	} else {
 		return diag.Errorf("Error retrieving asset permission ID after creation, response: %v", result)
 	}
@@ -104,92 +81,44 @@ func resourceAssetPermissionCreate(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceAssetPermissionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/perms/asset-permissions/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 200 OK status code
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error fetching asset permission: %s", resp.Status)
-	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/perms/asset-permissions/%s/", id), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return apiDiagnostics(err, "Error fetching asset permission", nil)
 	}
 
 	// Update resource data with fetched values
-	d.Set("name", result["name"].(string))
-	d.Set("is_active", result["is_active"].(bool))
-	d.Set("users_display", result["users_display"].([]interface{}))
-	d.Set("assets_display", result["assets_display"].([]interface{}))
-	d.Set("system_users_display", result["system_users_display"].([]interface{}))
+	d.Set("name", result["name"])
+	d.Set("is_active", result["is_active"])
+	d.Set("users_display", result["users_display"])
+	d.Set("assets_display", result["assets_display"])
+	if _, ok := result["system_users_display"]; ok {
+		d.Set("system_users_display", result["system_users_display"])
+	}
+	setOrgID(d, api, result)
 
 	return diags
 }
 
 func resourceAssetPermissionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
-	permission := map[string]interface{}{
-		"name":                 d.Get("name").(string),
-		"is_active":            d.Get("is_active").(bool),
-		"users_display":        d.Get("users_display").([]interface{}),
-		"assets_display":       d.Get("assets_display").([]interface{}),
-		"system_users_display": d.Get("system_users_display").([]interface{}),
-	}
+	permission := expandAssetPermission(d, m.(*Config).ServerVersion)
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/perms/asset-permissions/%s/", c.BaseURL, id)
-	jsonValue, _ := json.Marshal(permission)
-
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 200 OK status code
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error updating asset permission: %s", resp.Status)
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/perms/asset-permissions/%s/", id), permission, nil); err != nil {
+		return apiDiagnostics(err, "Error updating asset permission", nil)
 	}
 
 	resourceAssetPermissionRead(ctx, d, m)
@@ -198,38 +127,41 @@ func resourceAssetPermissionUpdate(ctx context.Context, d *schema.ResourceData,
 }
 
 func resourceAssetPermissionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/perms/asset-permissions/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/perms/asset-permissions/%s/", id)); err != nil {
+		return apiDiagnostics(err, "Error deleting asset permission", nil)
 	}
 
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
+	d.SetId("") // Mark resource as destroyed
+	return diags
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
+// resourceAssetPermissionCustomizeDiff rejects system users on JumpServer v3,
+// where permissions grant accounts instead.
+func resourceAssetPermissionCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c, ok := m.(*Config)
+	if !ok || !c.ServerVersion.known() || c.ServerVersion.major < 3 {
+		return nil
 	}
-	defer resp.Body.Close()
-
-	// Check for 204 No Content status code
-	if resp.StatusCode != http.StatusNoContent {
-		return diag.Errorf("Error deleting asset permission: %s", resp.Status)
+	if len(d.Get("system_users_display").([]interface{})) > 0 {
+		return fmt.Errorf("system_users_display is not supported by JumpServer v%s: system users were replaced by accounts in JumpServer v3", c.ServerVersion)
 	}
+	return nil
+}
 
-	d.SetId("") // Mark resource as destroyed
-	return diags
+func expandAssetPermission(d *schema.ResourceData, v serverVersion) map[string]interface{} {
+	permission := map[string]interface{}{
+		"name":           d.Get("name").(string),
+		"is_active":      d.Get("is_active").(bool),
+		"users_display":  d.Get("users_display").([]interface{}),
+		"assets_display": d.Get("assets_display").([]interface{}),
+	}
+	if !v.known() || v.major < 3 {
+		permission["system_users_display"] = d.Get("system_users_display").([]interface{})
+	}
+	return permission
 }
@@ -0,0 +1,176 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceDomain() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDomainCreate,
+		ReadContext:   resourceDomainRead,
+		UpdateContext: resourceDomainUpdate,
+		DeleteContext: resourceDomainDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"labels": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourceDomainCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Post(ctx, "/api/v1/assets/domains/", expandDomain(d), &result); err != nil {
+		return apiDiagnostics(err, "Failed to create domain", nil)
+	}
+
+	domainID, ok := result["id"].(string)
+	if !ok {
+		return diag.Errorf("No 'id' field found in domain creation response")
+	}
+	d.SetId(domainID)
+	setOrgID(d, api, result)
+	c.Lookups.invalidate("domain")
+
+	return append(diags, resourceDomainRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/domains/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read domain", nil)
+	}
+
+	if name, ok := result["name"].(string); ok {
+		d.Set("name", name)
+	}
+	if comment, ok := result["comment"].(string); ok {
+		d.Set("comment", comment)
+	}
+	if labels, ok := result["labels"].([]interface{}); ok {
+		d.Set("labels", flattenLabels(labels))
+	}
+	setOrgID(d, api, result)
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+
+	if err := api.Patch(ctx, fmt.Sprintf("/api/v1/assets/domains/%s/", d.Id()), expandDomain(d), nil); err != nil {
+		return apiDiagnostics(err, "Failed to update domain", nil)
+	}
+	if d.HasChange("name") {
+		c.Lookups.invalidate("domain")
+	}
+
+	return resourceDomainRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+	var diags diag.Diagnostics
+
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/domains/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete domain", nil)
+	}
+	c.Lookups.invalidate("domain")
+
+	d.SetId("")
+	return diags
+}
+
+func expandDomain(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"name":    d.Get("name").(string),
+		"comment": d.Get("comment").(string),
+		"labels":  expandLabels(d.Get("labels").(map[string]interface{})),
+	}
+}
+
+// expandLabels converts a label map into the "name:value" list JumpServer
+// expects.
+func expandLabels(labels map[string]interface{}) []string {
+	result := []string{}
+	for name, value := range labels {
+		result = append(result, name+":"+value.(string))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// flattenLabels reads labels returned either as "name:value" strings or as
+// {"name": ..., "value": ...} objects.
+func flattenLabels(labels []interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, l := range labels {
+		switch label := l.(type) {
+		case string:
+			if name, value, ok := strings.Cut(label, ":"); ok {
+				result[name] = value
+			}
+		case map[string]interface{}:
+			name, _ := label["name"].(string)
+			value, _ := label["value"].(string)
+			if name != "" {
+				result[name] = value
+			}
+		}
+	}
+	return result
+}
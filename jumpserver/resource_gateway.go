@@ -0,0 +1,258 @@
+package jumpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var gatewayAPIAttributes = map[string]string{
+	"domain": "domain_id",
+}
+
+func resourceGateway() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceGatewayCreate,
+		ReadContext:   resourceGatewayRead,
+		UpdateContext: resourceGatewayUpdate,
+		DeleteContext: resourceGatewayDelete,
+		CustomizeDiff: resourceGatewayCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"address": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"port": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  22,
+			},
+			"domain_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"platform": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"is_active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"accounts":  accountsSchema(),
+			"protocols": gatewayProtocolsSchema(),
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourceGatewayCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+	var diags diag.Diagnostics
+
+	gatewayData, err := expandGateway(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var result map[string]interface{}
+	if err := api.Post(ctx, "/api/v1/assets/gateways/", gatewayData, &result); err != nil {
+		return gatewayDiagnostics(err, "Failed to create gateway", d)
+	}
+
+	gatewayID, ok := result["id"].(string)
+	if !ok {
+		return diag.Errorf("No 'id' field found in gateway creation response")
+	}
+	d.SetId(gatewayID)
+	setOrgID(d, api, result)
+
+	return append(diags, resourceGatewayRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourceGatewayRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/gateways/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read gateway", nil)
+	}
+
+	if name, ok := result["name"].(string); ok {
+		d.Set("name", name)
+	}
+	if address, ok := result["address"].(string); ok {
+		d.Set("address", address)
+	}
+	if comment, ok := result["comment"].(string); ok {
+		d.Set("comment", comment)
+	}
+	if isActive, ok := result["is_active"].(bool); ok {
+		d.Set("is_active", isActive)
+	}
+	if platformID, _ := flattenRef(result["platform"]); platformID != "" {
+		if platform, err := strconv.Atoi(platformID); err == nil {
+			d.Set("platform", platform)
+		}
+	}
+	if domainID, _ := flattenRef(result["domain"]); domainID != "" {
+		d.Set("domain_id", domainID)
+	}
+
+	// The SSH protocol is exposed as "port", the others as "protocols".
+	if protocols, ok := result["protocols"].([]interface{}); ok {
+		var others []interface{}
+		for _, p := range flattenProtocols(protocols) {
+			proto := p.(map[string]interface{})
+			if proto["name"] == "ssh" {
+				if port, ok := proto["port"].(float64); ok {
+					d.Set("port", int(port))
+				}
+				continue
+			}
+			others = append(others, proto)
+		}
+		d.Set("protocols", others)
+	}
+	if accounts, ok := result["accounts"].([]interface{}); ok {
+		d.Set("accounts", flattenAccounts(accounts, d.Get("accounts").([]interface{})))
+	}
+	setOrgID(d, api, result)
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourceGatewayUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+
+	gatewayData, err := expandGateway(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/assets/gateways/%s/", d.Id()), gatewayData, nil); err != nil {
+		return gatewayDiagnostics(err, "Failed to update gateway", d)
+	}
+
+	return resourceGatewayRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourceGatewayDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/gateways/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete gateway", nil)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+func resourceGatewayCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	return validateAccounts(d)
+}
+
+// gatewayProtocolsSchema is the protocols block of gateways, which leaves
+// out SSH as it is set by port.
+func gatewayProtocolsSchema() *schema.Schema {
+	protocols := protocolsSchema()
+	protocols.Elem.(*schema.Resource).Schema["name"].ValidateFunc = validation.StringNotInSlice([]string{"ssh"}, true)
+	return protocols
+}
+
+// expandGateway builds the gateway payload. Without an explicit platform the
+// built-in "Gateway" platform is used. The SSH protocol built from port comes
+// after the configured protocols, so their indexes match the payload.
+func expandGateway(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData) (map[string]interface{}, error) {
+	platform := d.Get("platform").(int)
+	if platform == 0 {
+		var err error
+		platform, err = findPlatformID(ctx, c, api, "Gateway")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var protocols []map[string]interface{}
+	if v, ok := d.GetOk("protocols"); ok {
+		protocols = expandProtocols(v.([]interface{}))
+	}
+	protocols = append(protocols, map[string]interface{}{"name": "ssh", "port": d.Get("port").(int)})
+
+	gatewayData := map[string]interface{}{
+		"name":      d.Get("name").(string),
+		"address":   d.Get("address").(string),
+		"domain":    d.Get("domain_id").(string),
+		"platform":  platform,
+		"is_active": d.Get("is_active").(bool),
+		"comment":   d.Get("comment").(string),
+		"protocols": protocols,
+	}
+	if v, ok := d.GetOk("accounts"); ok {
+		gatewayData["accounts"] = expandAccounts(d, v.([]interface{}))
+	}
+	return gatewayData, nil
+}
+
+// gatewayDiagnostics converts an error of the gateway API into diagnostics,
+// pointing errors of the SSH protocol, the last one of the payload, at port.
+func gatewayDiagnostics(err error, summary string, d *schema.ResourceData) diag.Diagnostics {
+	sshIndex := len(d.Get("protocols").([]interface{}))
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		for i, fe := range apiErr.FieldErrors {
+			if len(fe.Field) >= 2 && fe.Field[0] == "protocols" && fe.Field[1] == sshIndex {
+				apiErr.FieldErrors[i].Field = []interface{}{"port"}
+			}
+		}
+	}
+	return apiDiagnostics(err, summary, gatewayAPIAttributes)
+}
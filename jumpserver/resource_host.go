@@ -1,25 +1,47 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+var hostAPIAttributes = map[string]string{
+	"domain": "domain_name",
+	"nodes":  "node_names",
+}
+
+// hostNodeAttributes are the mutually exclusive ways of placing a host in
+// the node tree.
+var hostNodeAttributes = []string{"node_name", "node_names", "node_ids"}
+
 func resourceHost() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceHostCreate,
 		ReadContext:   resourceHostRead,
 		UpdateContext: resourceHostUpdate,
 		DeleteContext: resourceHostDelete,
+		CustomizeDiff: resourceHostCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceHostImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -33,8 +55,16 @@ func resourceHost() *schema.Resource {
 				Optional: true,
 			},
 			"platform": {
-				Type:     schema.TypeInt,
-				Required: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"platform", "platform_name"},
+			},
+			"platform_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"platform", "platform_name"},
 			},
 
 			"domain_name": {
@@ -42,8 +72,17 @@ func resourceHost() *schema.Resource {
 				Required: true,
 			},
 			"node_name": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: hostNodeAttributes,
+				Deprecated:   "Use node_names, which supports hosts placed in several nodes.",
+			},
+			"node_names": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MinItems:     1,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ExactlyOneOf: hostNodeAttributes,
 			},
 
 			"domain_id": {
@@ -51,61 +90,109 @@ func resourceHost() *schema.Resource {
 				Computed: true,
 			},
 			"node_ids": {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Computed:     true,
+				MinItems:     1,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ExactlyOneOf: hostNodeAttributes,
 			},
 
-			"accounts": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"on_invalid": {
-							Type:     schema.TypeString,
-							Optional: true,
-							Default:  "error",
-						},
-						"is_active": {
-							Type:     schema.TypeBool,
-							Optional: true,
-							Default:  true,
-						},
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"username": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"secret_type": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"secret": {
-							Type:      schema.TypeString,
-							Required:  true,
-							Sensitive: true,
-						},
-					},
+			"accounts":  accountsSchema(),
+			"protocols": protocolsSchema(),
+		},
+	}
+}
+
+// accountsSchema is the inline accounts block shared by assets with login
+// accounts (hosts, gateways). An account is either described in full or
+// created from an account template, which provides the fields left unset.
+func accountsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"template_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"on_invalid": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "error",
+				},
+				"is_active": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"name": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Computed: true,
+				},
+				"username": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Computed: true,
+				},
+				"secret_type": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Computed: true,
+				},
+				"secret": {
+					Type:       schema.TypeString,
+					Optional:   true,
+					Sensitive:  true,
+					StateFunc:  hashSecret,
+					Deprecated: "Manage account secrets with the jumpserver_account resource, which can also have them generated.",
 				},
 			},
+		},
+	}
+}
 
-			"protocols": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"port": {
-							Type:     schema.TypeInt,
-							Required: true,
-						},
-					},
+// validateAccounts checks that every configured account either references a
+// template or sets name, username and secret_type, which JumpServer would
+// otherwise only reject at apply time.
+func validateAccounts(d *schema.ResourceDiff) error {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	accounts := config.GetAttr("accounts")
+	if accounts.IsNull() || !accounts.IsKnown() {
+		return nil
+	}
+	for i, account := range accounts.AsValueSlice() {
+		if !account.IsKnown() || !account.GetAttr("template_id").IsNull() {
+			continue
+		}
+		for _, attr := range []string{"name", "username", "secret_type"} {
+			if account.GetAttr(attr).IsNull() {
+				return fmt.Errorf("accounts.%d: %s is required unless template_id is set", i, attr)
+			}
+		}
+	}
+	return nil
+}
+
+// protocolsSchema is the protocols block shared by assets.
+func protocolsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"port": {
+					Type:     schema.TypeInt,
+					Required: true,
 				},
 			},
 		},
@@ -117,16 +204,21 @@ func resourceHost() *schema.Resource {
 // -------------------------------------------------------------------
 func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 	var diags diag.Diagnostics
 
 	domainName := d.Get("domain_name").(string)
-	domainID, err := findDomainIDByName(c, domainName)
+	domainID, err := c.Lookups.get(ctx, api, "domain", domainName, findDomainIDByName)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	nodeName := d.Get("node_name").(string)
-	nodeID, err := findNodeIDByName(c, nodeName)
+	nodeIDs, err := expandHostNodes(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	platformID, err := expandHostPlatform(ctx, c, api, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -134,9 +226,9 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 	hostData := map[string]interface{}{
 		"name":     d.Get("name").(string),
 		"address":  d.Get("address").(string),
-		"platform": d.Get("platform").(int),
+		"platform": platformID,
 		"domain":   domainID,
-		"nodes":    []string{nodeID},
+		"nodes":    nodeIDs,
 	}
 
 	if v, ok := d.GetOk("comment"); ok {
@@ -144,46 +236,16 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 
 	if v, ok := d.GetOk("accounts"); ok {
-		hostData["accounts"] = expandAccounts(v.([]interface{}))
+		hostData["accounts"] = expandAccounts(d, v.([]interface{}))
 	}
 
 	if v, ok := d.GetOk("protocols"); ok {
 		hostData["protocols"] = expandProtocols(v.([]interface{}))
 	}
 
-	jsonValue, err := json.Marshal(hostData)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	url := fmt.Sprintf("%s/api/v1/assets/hosts/", c.BaseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Failed to create host in JumpServer. HTTP status: %d", resp.StatusCode)
-	}
-
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Post(ctx, "/api/v1/assets/hosts/", hostData, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create host in JumpServer", hostAttributes(d))
 	}
 
 	hostID, ok := result["id"].(string)
@@ -191,9 +253,11 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 		return diag.Errorf("No 'id' field found in host creation response")
 	}
 	d.SetId(hostID)
+	setOrgID(d, api, result)
 
 	d.Set("domain_id", domainID)
-	d.Set("node_ids", []string{nodeID})
+	d.Set("node_ids", nodeIDs)
+	d.Set("platform", platformID)
 
 	return diags
 }
@@ -203,38 +267,16 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 // -------------------------------------------------------------------
 func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 	var diags diag.Diagnostics
 
-	url := fmt.Sprintf("%s/api/v1/assets/hosts/%s/", c.BaseURL, d.Id())
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		d.SetId("")
-		return diags
-	} else if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Failed to read host. HTTP status: %d", resp.StatusCode)
-	}
-
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/hosts/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read host", nil)
 	}
 
 	if name, ok := result["name"].(string); ok {
@@ -246,22 +288,31 @@ func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	if comment, ok := result["comment"].(string); ok {
 		d.Set("comment", comment)
 	}
-	if platform, ok := result["platform"].(float64); ok {
-		d.Set("platform", int(platform))
+	if platformID, platformName := flattenRef(result["platform"]); platformID != "" {
+		if platform, err := strconv.Atoi(platformID); err == nil {
+			d.Set("platform", platform)
+		}
+		if platformName != "" && !strings.EqualFold(d.Get("platform_name").(string), platformName) {
+			d.Set("platform_name", platformName)
+		}
 	}
-	if domain, ok := result["domain"].(string); ok {
-		d.Set("domain_id", domain)
+	if domainID, domainName := flattenRef(result["domain"]); domainID != "" {
+		d.Set("domain_id", domainID)
+		if domainName != "" && !strings.EqualFold(d.Get("domain_name").(string), domainName) {
+			d.Set("domain_name", domainName)
+		}
 	}
 	if nodes, ok := result["nodes"].([]interface{}); ok {
-		d.Set("node_ids", nodes)
+		flattenHostNodes(ctx, c, api, d, nodes)
 	}
 
 	if accounts, ok := result["accounts"].([]interface{}); ok {
-		d.Set("accounts", flattenAccounts(accounts))
+		d.Set("accounts", flattenAccounts(accounts, d.Get("accounts").([]interface{})))
 	}
 	if protocols, ok := result["protocols"].([]interface{}); ok {
 		d.Set("protocols", flattenProtocols(protocols))
 	}
+	setOrgID(d, api, result)
 
 	return diags
 }
@@ -271,17 +322,13 @@ func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}
 // -------------------------------------------------------------------
 func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*Config)
+	api := orgClient(c, d)
 
 	domainID := d.Get("domain_id").(string)
-	nodeIDsRaw := d.Get("node_ids").([]interface{})
-	var nodeID string
-	if len(nodeIDsRaw) > 0 {
-		nodeID = nodeIDsRaw[0].(string)
-	}
 
 	if d.HasChange("domain_name") {
 		newDomainName := d.Get("domain_name").(string)
-		foundID, err := findDomainIDByName(c, newDomainName)
+		foundID, err := c.Lookups.get(ctx, api, "domain", newDomainName, findDomainIDByName)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -289,22 +336,23 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		d.Set("domain_id", foundID)
 	}
 
-	if d.HasChange("node_name") {
-		newNodeName := d.Get("node_name").(string)
-		foundID, err := findNodeIDByName(c, newNodeName)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		nodeID = foundID
-		d.Set("node_ids", []string{foundID})
+	nodeIDs, err := expandHostNodes(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("node_ids", nodeIDs)
+
+	platformID, err := expandHostPlatform(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	hostData := map[string]interface{}{
 		"name":     d.Get("name").(string),
 		"address":  d.Get("address").(string),
-		"platform": d.Get("platform").(int),
+		"platform": platformID,
 		"domain":   domainID,
-		"nodes":    []string{nodeID},
+		"nodes":    nodeIDs,
 	}
 
 	if v, ok := d.GetOk("comment"); ok {
@@ -312,40 +360,14 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 
 	if v, ok := d.GetOk("accounts"); ok {
-		hostData["accounts"] = expandAccounts(v.([]interface{}))
+		hostData["accounts"] = expandAccounts(d, v.([]interface{}))
 	}
 	if v, ok := d.GetOk("protocols"); ok {
 		hostData["protocols"] = expandProtocols(v.([]interface{}))
 	}
 
-	jsonValue, err := json.Marshal(hostData)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	url := fmt.Sprintf("%s/api/v1/assets/hosts/%s/", c.BaseURL, d.Id())
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Failed to update host. HTTP status: %d", resp.StatusCode)
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/assets/hosts/%s/", d.Id()), hostData, nil); err != nil {
+		return apiDiagnostics(err, "Failed to update host", hostAttributes(d))
 	}
 
 	return resourceHostRead(ctx, d, m)
@@ -355,137 +377,354 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 // Delete
 // -------------------------------------------------------------------
 func resourceHostDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 	var diags diag.Diagnostics
 
-	url := fmt.Sprintf("%s/api/v1/assets/hosts/%s/", c.BaseURL, d.Id())
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/hosts/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete host", nil)
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
+
+	d.SetId("")
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Nodes
+// -------------------------------------------------------------------
+
+// resourceHostCustomizeDiff validates the accounts and marks node_ids as
+// unknown when the nodes are given by name and change, as the new IDs are
+// only known once resolved. The same goes for the platform given by name.
+func resourceHostCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := validateAccounts(d); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	config := d.GetRawConfig()
+	if !isConfigured(config, "node_ids") && (d.HasChange("node_names") || d.HasChange("node_name")) {
+		if err := d.SetNewComputed("node_ids"); err != nil {
+			return err
 		}
 	}
 
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
+	// platform and platform_name describe the same platform: when one is
+	// changed the other is only known once applied.
+	if !isConfigured(config, "platform") && d.HasChange("platform_name") {
+		return d.SetNewComputed("platform")
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		return diag.Errorf("Failed to delete host. HTTP status: %d", resp.StatusCode)
+	if !isConfigured(config, "platform_name") && d.HasChange("platform") {
+		return d.SetNewComputed("platform_name")
 	}
+	return nil
+}
 
-	d.SetId("")
-	return diags
+// hostAttributes returns hostAPIAttributes with the nodes and platform
+// fields pointing at the attributes the host is configured with.
+func hostAttributes(d *schema.ResourceData) map[string]string {
+	attrs := make(map[string]string, len(hostAPIAttributes)+1)
+	for k, v := range hostAPIAttributes {
+		attrs[k] = v
+	}
+	if isConfigured(d.GetRawConfig(), "platform_name") {
+		attrs["platform"] = "platform_name"
+	}
+	for _, attr := range hostNodeAttributes {
+		if isConfigured(d.GetRawConfig(), attr) {
+			attrs["nodes"] = attr
+			break
+		}
+	}
+	return attrs
 }
 
-// -------------------------------------------------------------------
-// Get domain_id / node_id from domain_name / node_name
-// -------------------------------------------------------------------
-func findDomainIDByName(c *Config, domainName string) (string, error) {
-	url := fmt.Sprintf("%s/api/v1/assets/domains/", c.BaseURL)
-	req, err := http.NewRequest("GET", url, nil)
+// expandHostNodes returns the IDs of the nodes the host belongs to.
+//
+// node_names and node_ids are authoritative: the host is placed in exactly
+// those nodes. The deprecated node_name only manages one membership, so when
+// it changes the old node is swapped for the new one and nodes the host was
+// added to outside Terraform are kept.
+func expandHostNodes(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData) ([]string, error) {
+	if v, ok := d.GetOk("node_names"); ok {
+		var nodeIDs []string
+		for _, name := range v.(*schema.Set).List() {
+			nodeID, err := resolveNodeID(ctx, c, api, name.(string))
+			if err != nil {
+				return nil, err
+			}
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		return nodeIDs, nil
+	}
+	if isConfigured(d.GetRawConfig(), "node_ids") {
+		return expandStringSet(d.Get("node_ids").(*schema.Set)), nil
+	}
+
+	nodeName := d.Get("node_name").(string)
+	nodeID, err := resolveNodeID(ctx, c, api, nodeName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return "", err
+	if d.IsNewResource() {
+		return []string{nodeID}, nil
+	}
+
+	// Keep the memberships found at the last read, replacing the old node.
+	var oldID string
+	if old, _ := d.GetChange("node_name"); old.(string) != "" && old.(string) != nodeName {
+		oldID, _ = resolveNodeID(ctx, c, api, old.(string))
+	}
+	nodeIDs := []string{nodeID}
+	if old, _ := d.GetChange("node_ids"); old != nil {
+		for _, id := range expandStringSet(old.(*schema.Set)) {
+			if id != nodeID && id != oldID {
+				nodeIDs = append(nodeIDs, id)
+			}
 		}
 	}
+	return nodeIDs, nil
+}
 
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+// expandHostPlatform returns the ID of the host platform, resolving
+// platform_name when the platform is given by name.
+func expandHostPlatform(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData) (int, error) {
+	if isConfigured(d.GetRawConfig(), "platform_name") {
+		return findPlatformID(ctx, c, api, d.Get("platform_name").(string))
 	}
-	defer resp.Body.Close()
+	return d.Get("platform").(int), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to list domains, status=%d", resp.StatusCode)
+// resolveNodeID returns the ID of a node given by ID, name or full path.
+func resolveNodeID(ctx context.Context, c *Config, api *client.Client, node string) (string, error) {
+	if isUUID(node) {
+		return node, nil
 	}
+	return c.Lookups.get(ctx, api, "node", node, findNodeIDByName)
+}
 
-	var domains []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&domains); err != nil {
-		return "", err
+// flattenHostNodes sets node_ids to the nodes the host belongs to and keeps
+// the node names in step with them.
+func flattenHostNodes(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData, nodes []interface{}) {
+	var nodeIDs []string
+	nodeNames := map[string]string{}
+	for _, node := range nodes {
+		nodeID, nodeName := flattenRef(node)
+		nodeIDs = append(nodeIDs, nodeID)
+		nodeNames[nodeID] = nodeName
 	}
+	d.Set("node_ids", nodeIDs)
 
-	for _, dom := range domains {
-		if domName, ok := dom["name"].(string); ok {
-			if strings.EqualFold(domName, domainName) {
-				if id, idOk := dom["id"].(string); idOk {
-					return id, nil
+	// node_name only tracks one membership; it is replaced only when the
+	// host left that node.
+	if currentName := d.Get("node_name").(string); currentName != "" {
+		if strings.HasPrefix(currentName, "/") || isUUID(currentName) {
+			return
+		}
+		for _, name := range nodeNames {
+			if strings.EqualFold(name, currentName) {
+				return
+			}
+		}
+		if len(nodeIDs) > 0 && nodeNames[nodeIDs[0]] != "" {
+			d.Set("node_name", nodeNames[nodeIDs[0]])
+		}
+		return
+	}
+
+	// Hosts placed by node_ids need nothing more. Imported hosts only get
+	// node_ids too: which attribute the configuration uses is not known yet,
+	// and filling another one would show up as a diff against it.
+	configured := d.Get("node_names").(*schema.Set)
+	if configured.Len() == 0 {
+		return
+	}
+
+	// Names that still designate one of the nodes are kept as written, so
+	// paths and IDs do not cause a diff. Nodes the host left are dropped and
+	// nodes it was added to outside Terraform are added by name, so both
+	// show up in the plan.
+	covered := map[string]bool{}
+	var names []string
+	for _, v := range configured.List() {
+		name := v.(string)
+		nodeID := ""
+		switch {
+		case isUUID(name):
+			nodeID = name
+		case strings.HasPrefix(name, "/"):
+			nodeID, _ = resolveNodeID(ctx, c, api, name)
+		default:
+			for _, id := range nodeIDs {
+				if strings.EqualFold(nodeNames[id], name) && !covered[id] {
+					nodeID = id
+					break
 				}
-				return "", fmt.Errorf("domain '%s' found but has no 'id'", domainName)
 			}
 		}
+		if _, member := nodeNames[nodeID]; member && !covered[nodeID] {
+			covered[nodeID] = true
+			names = append(names, name)
+		}
+	}
+	for _, nodeID := range nodeIDs {
+		if covered[nodeID] {
+			continue
+		}
+		if nodeNames[nodeID] != "" {
+			names = append(names, nodeNames[nodeID])
+		} else {
+			names = append(names, nodeID)
+		}
+	}
+	d.Set("node_names", names)
+}
+
+// isConfigured reports whether attr is set in the raw configuration of a
+// resource.
+func isConfigured(config cty.Value, attr string) bool {
+	if config.IsNull() || !config.IsKnown() {
+		return false
 	}
-	return "", fmt.Errorf("domain '%s' not found in JumpServer", domainName)
+	return !config.GetAttr(attr).IsNull()
 }
 
-func findNodeIDByName(c *Config, nodeName string) (string, error) {
-	url := fmt.Sprintf("%s/api/v1/assets/nodes/", c.BaseURL)
-	req, err := http.NewRequest("GET", url, nil)
+func expandStringSet(set *schema.Set) []string {
+	var result []string
+	for _, v := range set.List() {
+		result = append(result, v.(string))
+	}
+	return result
+}
+
+// -------------------------------------------------------------------
+// Import
+// -------------------------------------------------------------------
+
+// resourceHostImport accepts "<id>", "<org>/<id>" or "<org>/<name>", where
+// org is an organization ID or name.
+func resourceHostImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if _, err := importStateWithOrg(ctx, d, m); err != nil {
+		return nil, err
+	}
+	if isUUID(d.Id()) {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	hostID, err := findHostIDByName(ctx, orgClient(m.(*Config), d), d.Id())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return "", err
+	d.SetId(hostID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func findHostIDByName(ctx context.Context, api *client.Client, hostName string) (string, error) {
+	var ids []string
+	err := api.List(ctx, "/api/v1/assets/hosts/", url.Values{"name": {hostName}}, func(host map[string]interface{}) bool {
+		if name, ok := host["name"].(string); ok && name == hostName {
+			if id, idOk := host["id"].(string); idOk {
+				ids = append(ids, id)
+			}
 		}
+		return true
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list hosts: %w", err)
 	}
 
-	client := c.NewHTTPClient()
-	resp, err := client.Do(req)
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("host '%s' not found in JumpServer", hostName)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("found %d hosts named '%s', import by ID instead", len(ids), hostName)
+	}
+}
+
+// -------------------------------------------------------------------
+// Get domain_id / node_id from domain_name / node_name
+// -------------------------------------------------------------------
+func findDomainIDByName(ctx context.Context, api *client.Client, domainName string) (string, error) {
+	dom, err := findFirst(ctx, api, "/api/v1/assets/domains/", url.Values{"search": {domainName}}, nameMatcher("name", domainName))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to list domains: %w", err)
+	}
+	if dom == nil {
+		return "", fmt.Errorf("domain '%s' not found in JumpServer", domainName)
 	}
-	defer resp.Body.Close()
+	if id, ok := dom["id"].(string); ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("domain '%s' found but has no 'id'", domainName)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to list nodes, status=%d", resp.StatusCode)
+// findNodeIDByName resolves a node by its bare name, or by its full path
+// (e.g. "/Default/prod/db") when nodeName starts with a slash.
+func findNodeIDByName(ctx context.Context, api *client.Client, nodeName string) (string, error) {
+	var node map[string]interface{}
+	if strings.HasPrefix(nodeName, "/") {
+		var err error
+		node, err = findNodeByPath(ctx, api, nodeName)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		node, err = findFirst(ctx, api, "/api/v1/assets/nodes/", url.Values{"search": {nodeName}}, nameMatcher("name", nodeName))
+		if err != nil {
+			return "", fmt.Errorf("failed to list nodes: %w", err)
+		}
+		if node == nil {
+			return "", fmt.Errorf("node '%s' not found in JumpServer", nodeName)
+		}
 	}
 
-	var nodes []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
-		return "", err
+	if id, ok := node["id"].(string); ok {
+		return id, nil
 	}
+	return "", fmt.Errorf("node '%s' found but has no 'id'", nodeName)
+}
 
-	for _, node := range nodes {
-		if nodeNameAPI, ok := node["name"].(string); ok {
-			if strings.EqualFold(nodeNameAPI, nodeName) {
-				if id, idOk := node["id"].(string); idOk {
-					return id, nil
-				}
-				return "", fmt.Errorf("node '%s' found but has no 'id'", nodeName)
-			}
-		}
+func findPlatformIDByName(ctx context.Context, api *client.Client, platformName string) (int, error) {
+	platform, err := findFirst(ctx, api, "/api/v1/assets/platforms/", url.Values{"search": {platformName}}, nameMatcher("name", platformName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list platforms: %w", err)
+	}
+	if platform == nil {
+		return 0, fmt.Errorf("platform '%s' not found in JumpServer", platformName)
+	}
+	if id, ok := platform["id"].(float64); ok {
+		return int(id), nil
 	}
-	return "", fmt.Errorf("node '%s' not found in JumpServer", nodeName)
+	return 0, fmt.Errorf("platform '%s' found but has no 'id'", platformName)
 }
 
-func expandAccounts(list []interface{}) []map[string]interface{} {
+// expandAccounts builds the inline accounts payload. Fields left empty are
+// omitted, so accounts created from a template take them from it.
+func expandAccounts(d *schema.ResourceData, list []interface{}) []map[string]interface{} {
 	var result []map[string]interface{}
-	for _, item := range list {
+	for i, item := range list {
 		m := item.(map[string]interface{})
 		acc := map[string]interface{}{
-			"on_invalid":  m["on_invalid"].(string),
-			"is_active":   m["is_active"].(bool),
-			"name":        m["name"].(string),
-			"username":    m["username"].(string),
-			"secret_type": m["secret_type"].(string),
-			"secret":      m["secret"].(string),
+			"on_invalid": m["on_invalid"].(string),
+			"is_active":  m["is_active"].(bool),
+		}
+		if v := m["template_id"].(string); v != "" {
+			acc["template"] = v
+		}
+		for _, field := range []string{"name", "username", "secret_type"} {
+			if v := m[field].(string); v != "" {
+				acc[field] = v
+			}
+		}
+		// State only holds a hash of the secret: the configured secret is
+		// known, and sent, only when it changes.
+		if d.HasChange(fmt.Sprintf("accounts.%d.secret", i)) {
+			if v := m["secret"].(string); v != "" {
+				acc["secret"] = v
+			}
 		}
 		result = append(result, acc)
 	}
@@ -505,16 +744,54 @@ func expandProtocols(list []interface{}) []map[string]interface{} {
 	return result
 }
 
-func flattenAccounts(accounts []interface{}) []interface{} {
-	var result []interface{}
+// flattenAccounts converts the accounts returned by JumpServer that match
+// an account of current, the accounts known so far, by template or name.
+// Other accounts of the asset, such as the ones managed with
+// jumpserver_account, are left out. The secret and on_invalid are
+// write-only, so the values of the matching account are kept to avoid a
+// permanent diff, the secret as a hash.
+func flattenAccounts(accounts []interface{}, current []interface{}) []interface{} {
+	byName := map[string]map[string]interface{}{}
+	byTemplate := map[string]map[string]interface{}{}
 	for _, a := range accounts {
 		m := a.(map[string]interface{})
+		if flattenChoice(m["source"]) == "template" {
+			if templateID, _ := flattenRef(m["source_id"]); templateID != "" {
+				byTemplate[templateID] = m
+			}
+		}
+		byName[fmt.Sprint(m["name"])] = m
+	}
+
+	var result []interface{}
+	for _, a := range current {
+		prev, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		templateID, _ := prev["template_id"].(string)
+		m := byTemplate[templateID]
+		if m == nil {
+			if name, _ := prev["name"].(string); name != "" {
+				m = byName[name]
+			}
+		}
+		if m == nil {
+			continue
+		}
+
+		onInvalid := prev["on_invalid"]
+		if onInvalid == nil {
+			onInvalid = "error"
+		}
 		acc := map[string]interface{}{
-			"on_invalid":  m["on_invalid"],
+			"template_id": templateID,
+			"on_invalid":  onInvalid,
 			"is_active":   m["is_active"],
 			"name":        m["name"],
 			"username":    m["username"],
-			"secret_type": m["secret_type"],
+			"secret_type": flattenChoice(m["secret_type"]),
+			"secret":      hashedSecret(prev["secret"]),
 		}
 		result = append(result, acc)
 	}
@@ -533,3 +810,19 @@ func flattenProtocols(protocols []interface{}) []interface{} {
 	}
 	return result
 }
+
+// flattenRef extracts the ID and name of a related object, which JumpServer
+// returns either as a bare ID or as an object such as {"id": ..., "name": ...}.
+func flattenRef(v interface{}) (id, name string) {
+	switch ref := v.(type) {
+	case string:
+		return ref, ""
+	case float64:
+		return strconv.FormatFloat(ref, 'f', -1, 64), ""
+	case map[string]interface{}:
+		id, _ = flattenRef(ref["id"])
+		name, _ = ref["name"].(string)
+		return id, name
+	}
+	return "", ""
+}
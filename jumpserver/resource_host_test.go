@@ -0,0 +1,192 @@
+package jumpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const testHostID = "5fd4b6a1-8c36-4f8e-9a7e-6a2bb0f7c1d2"
+
+// hostServer serves a host placed in the db and web nodes and records the
+// nodes sent by the last update. Updates fail with a 400 and updateError as
+// body when it is set.
+func hostServer(t *testing.T, updateError string) (*Config, *[]string) {
+	t.Helper()
+	var updated []string
+	host := map[string]interface{}{
+		"id":       testHostID,
+		"name":     "db1",
+		"address":  "10.0.0.1",
+		"platform": map[string]interface{}{"id": 1, "name": "Linux"},
+		"domain":   map[string]interface{}{"id": "dom-1", "name": "prod"},
+		"nodes": []interface{}{
+			map[string]interface{}{"id": "node-db", "name": "db"},
+			map[string]interface{}{"id": "node-web", "name": "web"},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/assets/hosts/"+testHostID+"/" && r.Method == http.MethodPut:
+			var body struct {
+				Nodes []string `json:"nodes"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding update: %v", err)
+			}
+			if updateError != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(updateError))
+				return
+			}
+			updated = body.Nodes
+			_ = json.NewEncoder(w).Encode(host)
+		case r.URL.Path == "/api/v1/assets/hosts/"+testHostID+"/":
+			_ = json.NewEncoder(w).Encode(host)
+		case r.URL.Path == "/api/v1/assets/nodes/":
+			_ = json.NewEncoder(w).Encode([]interface{}{
+				map[string]interface{}{"id": "node-db", "name": "db", "value": "db"},
+			})
+		case r.URL.Path == "/api/v1/assets/platforms/":
+			_ = json.NewEncoder(w).Encode([]interface{}{
+				map[string]interface{}{"id": 1, "name": "Linux"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	api, err := client.New(client.Config{BaseURL: srv.URL, Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Config{Client: api, Lookups: newLookupCache(), ServerVersion: parseServerVersion("v3.10.1")}, &updated
+}
+
+// hostConfig returns the configuration made of attrs, also recording it as
+// the raw configuration Terraform sends along with the state.
+func hostConfig(t *testing.T, r *schema.Resource, state *terraform.InstanceState, attrs map[string]cty.Value) *terraform.ResourceConfig {
+	t.Helper()
+	config, err := r.CoreConfigSchema().CoerceValue(cty.ObjectVal(attrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.RawConfig = config
+	return terraform.NewResourceConfigShimmed(config, r.CoreConfigSchema())
+}
+
+func TestHostImportWithNodeName(t *testing.T) {
+	c, updated := hostServer(t, "")
+	r := resourceHost()
+	ctx := context.Background()
+
+	// Import leaves only the ID in state before the refresh.
+	state, diags := r.RefreshWithoutUpgrade(ctx, &terraform.InstanceState{ID: testHostID, Attributes: map[string]string{"id": testHostID}}, c)
+	if diags.HasError() {
+		t.Fatalf("refresh: %v", diags)
+	}
+	if got := state.Attributes["node_ids.#"]; got != "2" {
+		t.Errorf("got %s node_ids, want 2", got)
+	}
+	if got := state.Attributes["node_names.#"]; got != "" && got != "0" {
+		t.Errorf("node_names set on import: %v", state.Attributes)
+	}
+
+	cfg := hostConfig(t, r, state, map[string]cty.Value{
+		"name":          cty.StringVal("db1"),
+		"address":       cty.StringVal("10.0.0.1"),
+		"platform_name": cty.StringVal("Linux"),
+		"domain_name":   cty.StringVal("prod"),
+		"node_name":     cty.StringVal("db"),
+	})
+	diff, err := r.Diff(ctx, state, cfg, c)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	for k := range diff.Attributes {
+		if k != "node_name" && k != "node_ids.#" {
+			t.Errorf("unexpected diff on %s: %#v", k, diff.Attributes[k])
+		}
+	}
+
+	if _, diags := r.Apply(ctx, state, diff, c); diags.HasError() {
+		t.Fatalf("apply: %v", diags)
+	}
+	nodes := append([]string(nil), *updated...)
+	sort.Strings(nodes)
+	if len(nodes) != 2 || nodes[0] != "node-db" || nodes[1] != "node-web" {
+		t.Errorf("update sent nodes %v, want the host kept in node-db and node-web", nodes)
+	}
+}
+
+func TestHostUpdateErrorOnPlatformName(t *testing.T) {
+	c, _ := hostServer(t, `{"platform": ["This platform is not supported"]}`)
+	r := resourceHost()
+	ctx := context.Background()
+
+	state, diags := r.RefreshWithoutUpgrade(ctx, &terraform.InstanceState{ID: testHostID, Attributes: map[string]string{"id": testHostID}}, c)
+	if diags.HasError() {
+		t.Fatalf("refresh: %v", diags)
+	}
+	cfg := hostConfig(t, r, state, map[string]cty.Value{
+		"name":          cty.StringVal("db1"),
+		"address":       cty.StringVal("10.0.0.1"),
+		"comment":       cty.StringVal("primary"),
+		"platform_name": cty.StringVal("Linux"),
+		"domain_name":   cty.StringVal("prod"),
+		"node_ids":      cty.SetVal([]cty.Value{cty.StringVal("node-db"), cty.StringVal("node-web")}),
+	})
+	diff, err := r.Diff(ctx, state, cfg, c)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	_, diags = r.Apply(ctx, state, diff, c)
+	want := cty.GetAttrPath("platform_name")
+	for _, d := range diags {
+		if d.AttributePath.Equals(want) {
+			return
+		}
+	}
+	t.Errorf("got diagnostics %v, want one pointing at platform_name", diags)
+}
+
+func TestHostAccountsValidation(t *testing.T) {
+	r := resourceHost()
+	for _, tc := range []struct {
+		account map[string]cty.Value
+		wantErr string
+	}{
+		{map[string]cty.Value{"template_id": cty.StringVal("tpl-root")}, ""},
+		{map[string]cty.Value{"name": cty.StringVal("root"), "username": cty.StringVal("root"), "secret_type": cty.StringVal("password")}, ""},
+		{map[string]cty.Value{"name": cty.StringVal("root"), "secret_type": cty.StringVal("password")}, "accounts.0: username is required unless template_id is set"},
+		{map[string]cty.Value{"username": cty.StringVal("root")}, "accounts.0: name is required unless template_id is set"},
+	} {
+		state := &terraform.InstanceState{}
+		cfg := hostConfig(t, r, state, map[string]cty.Value{
+			"name":          cty.StringVal("db1"),
+			"address":       cty.StringVal("10.0.0.1"),
+			"platform_name": cty.StringVal("Linux"),
+			"domain_name":   cty.StringVal("prod"),
+			"node_names":    cty.SetVal([]cty.Value{cty.StringVal("db")}),
+			"accounts":      cty.ListVal([]cty.Value{cty.ObjectVal(tc.account)}),
+		})
+		_, err := r.Diff(context.Background(), state, cfg, &Config{})
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.account, err)
+		}
+		if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+			t.Errorf("%v: got error %v, want %q", tc.account, err, tc.wantErr)
+		}
+	}
+}
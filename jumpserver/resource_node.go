@@ -0,0 +1,325 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var nodeAPIAttributes = map[string]string{
+	"value": "name",
+}
+
+func resourceNode() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNodeCreate,
+		ReadContext:   resourceNodeRead,
+		UpdateContext: resourceNodeUpdate,
+		DeleteContext: resourceNodeDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "path"},
+			},
+			"parent_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"path"},
+				// With path, the parent is read back from Jumpserver.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return d.Get("path").(string) != ""
+				},
+			},
+			"path": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateNodePath,
+			},
+
+			"key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"full_value": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"assets_amount": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourceNodeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+	var diags diag.Diagnostics
+
+	name, parentID, err := nodePlacement(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	path := "/api/v1/assets/nodes/"
+	if parentID != "" {
+		path = fmt.Sprintf("/api/v1/assets/nodes/%s/children/", parentID)
+	}
+
+	var result map[string]interface{}
+	if err := api.Post(ctx, path, map[string]interface{}{"value": name}, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create node", nodeAPIAttributes)
+	}
+
+	nodeID, ok := result["id"].(string)
+	if !ok {
+		return diag.Errorf("No 'id' field found in node creation response")
+	}
+	d.SetId(nodeID)
+	setOrgID(d, api, result)
+	c.Lookups.invalidate("node")
+
+	return append(diags, resourceNodeRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourceNodeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := orgClient(m.(*Config), d)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/nodes/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read node", nil)
+	}
+
+	if value, ok := result["value"].(string); ok {
+		d.Set("name", value)
+	}
+	if fullValue, ok := result["full_value"].(string); ok {
+		d.Set("full_value", fullValue)
+		if _, ok := d.GetOk("path"); ok {
+			d.Set("path", fullValue)
+		}
+	}
+	if amount, ok := result["assets_amount"].(float64); ok {
+		d.Set("assets_amount", int(amount))
+	}
+
+	key, _ := result["key"].(string)
+	d.Set("key", key)
+
+	// Nodes placed under the organization root without parent_id keep it
+	// empty, which is how the root is configured.
+	parentID := ""
+	if parentKey, ok := parentNodeKey(key); ok {
+		_, nested := parentNodeKey(parentKey)
+		if nested || d.Get("path").(string) != "" || d.Get("parent_id").(string) != "" {
+			parent, err := findNodeByKey(ctx, api, parentKey)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			parentID, _ = parent["id"].(string)
+		}
+	}
+	d.Set("parent_id", parentID)
+	setOrgID(d, api, result)
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourceNodeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+
+	name, parentID, err := nodePlacement(ctx, c, api, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChanges("parent_id", "path") {
+		parentID, moved, err := nodeMoveTarget(ctx, api, d.Get("key").(string), parentID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if moved {
+			moveData := map[string]interface{}{
+				"nodes": []string{d.Id()},
+			}
+			if err := api.Put(ctx, fmt.Sprintf("/api/v1/assets/nodes/%s/children/add/", parentID), moveData, nil); err != nil {
+				return apiDiagnostics(err, "Failed to move node", nil)
+			}
+			c.Lookups.invalidate("node")
+		}
+	}
+
+	if oldName, _ := d.GetChange("name"); name != oldName.(string) {
+		if err := api.Patch(ctx, fmt.Sprintf("/api/v1/assets/nodes/%s/", d.Id()), map[string]interface{}{"value": name}, nil); err != nil {
+			return apiDiagnostics(err, "Failed to rename node", nodeAPIAttributes)
+		}
+		c.Lookups.invalidate("node")
+	}
+
+	return resourceNodeRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourceNodeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	api := orgClient(c, d)
+	var diags diag.Diagnostics
+
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/nodes/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete node", nil)
+	}
+	c.Lookups.invalidate("node")
+
+	d.SetId("")
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Helpers
+// -------------------------------------------------------------------
+
+// nodePlacement returns the name of the node and the ID of its parent, taken
+// either from name/parent_id or from the full path, whose first segment is
+// the organization root node. An empty parent ID means the root node.
+func nodePlacement(ctx context.Context, c *Config, api *client.Client, d *schema.ResourceData) (string, string, error) {
+	path, ok := d.GetOk("path")
+	if !ok {
+		return d.Get("name").(string), d.Get("parent_id").(string), nil
+	}
+
+	parentPath, name := splitNodePath(path.(string))
+	if name == "" || parentPath == "" {
+		return "", "", fmt.Errorf("invalid node path '%s'", path)
+	}
+
+	parentID, err := c.Lookups.get(ctx, api, "node", parentPath, findNodeIDByName)
+	if err != nil {
+		return "", "", err
+	}
+	return name, parentID, nil
+}
+
+// nodeMoveTarget returns the ID of the parent the node with the given key
+// goes under, resolving an empty parentID to the root node, the first
+// segment of the key, and whether it differs from the current parent.
+// Moving re-keys the node and its whole subtree, so a node whose path only
+// changes by its name is renamed in place instead.
+func nodeMoveTarget(ctx context.Context, api *client.Client, key, parentID string) (string, bool, error) {
+	if parentID == "" {
+		root, err := findNodeByKey(ctx, api, strings.SplitN(key, ":", 2)[0])
+		if err != nil {
+			return "", false, err
+		}
+		parentID, _ = root["id"].(string)
+	}
+
+	currentKey, ok := parentNodeKey(key)
+	if !ok {
+		return parentID, true, nil
+	}
+	current, err := findNodeByKey(ctx, api, currentKey)
+	if err != nil {
+		return "", false, err
+	}
+	return parentID, current["id"] != parentID, nil
+}
+
+// validateNodePath rejects paths that do not name a node under the
+// organization root node, such as "/prod" or the root "/Default" itself.
+func validateNodePath(v interface{}, k string) ([]string, []error) {
+	parentPath, name := splitNodePath(v.(string))
+	if name == "" || parentPath == "" {
+		return nil, []error{fmt.Errorf("%q must be the full path of the node starting with the organization root node, e.g. \"/Default/%s\", got: %q", k, name, v)}
+	}
+	return nil, nil
+}
+
+// splitNodePath splits "/Default/prod/db" into "/Default/prod" and "db".
+func splitNodePath(path string) (string, string) {
+	path = "/" + strings.Trim(path, "/")
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "", path[1:]
+	}
+	return path[:i], path[i+1:]
+}
+
+// parentNodeKey returns the key of the parent of the node with the given key
+// ("1:3:5" -> "1:3"). Root nodes have no parent.
+func parentNodeKey(key string) (string, bool) {
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return "", false
+	}
+	return key[:i], true
+}
+
+func findNodeByKey(ctx context.Context, api *client.Client, key string) (map[string]interface{}, error) {
+	node, err := findFirst(ctx, api, "/api/v1/assets/nodes/", url.Values{"key": {key}}, func(node map[string]interface{}) bool {
+		nodeKey, ok := node["key"].(string)
+		return ok && nodeKey == key
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("node with key '%s' not found in JumpServer", key)
+	}
+	return node, nil
+}
+
+// findNodeByPath finds a node by its full path. The server is asked for
+// nodes matching the last path segment, then the full path is compared.
+func findNodeByPath(ctx context.Context, api *client.Client, path string) (map[string]interface{}, error) {
+	path = "/" + strings.Trim(path, "/")
+	_, name := splitNodePath(path)
+
+	node, err := findFirst(ctx, api, "/api/v1/assets/nodes/", url.Values{"search": {name}}, func(node map[string]interface{}) bool {
+		fullValue, ok := node["full_value"].(string)
+		return ok && fullValue == path
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("node '%s' not found in JumpServer", path)
+	}
+	return node, nil
+}
@@ -0,0 +1,316 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// platformAutomationFlags are the boolean automation settings of a platform.
+var platformAutomationFlags = []string{
+	"ansible_enabled",
+	"ping_enabled",
+	"gather_facts_enabled",
+	"push_account_enabled",
+	"change_secret_enabled",
+	"verify_account_enabled",
+	"gather_accounts_enabled",
+}
+
+func resourcePlatform() *schema.Resource {
+	automation := map[string]*schema.Schema{}
+	for _, flag := range platformAutomationFlags {
+		automation[flag] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		}
+	}
+
+	return &schema.Resource{
+		CreateContext: resourcePlatformCreate,
+		ReadContext:   resourcePlatformRead,
+		UpdateContext: resourcePlatformUpdate,
+		DeleteContext: resourcePlatformDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"category": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"charset": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "utf-8",
+				ValidateFunc: validation.StringInSlice([]string{"utf-8", "gbk"}, false),
+			},
+			"domain_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"su_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"su_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"su_enabled"},
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"protocols": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"port": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"primary": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"required": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"default": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"public": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+			"automation": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     &schema.Resource{Schema: automation},
+			},
+
+			"internal": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// -------------------------------------------------------------------
+// Create
+// -------------------------------------------------------------------
+func resourcePlatformCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	var diags diag.Diagnostics
+
+	platformData := expandPlatform(d)
+	platformData["category"] = d.Get("category").(string)
+	platformData["type"] = d.Get("type").(string)
+
+	var result map[string]interface{}
+	if err := c.Client.Post(ctx, "/api/v1/assets/platforms/", platformData, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create platform", nil)
+	}
+
+	platformID, _ := flattenRef(result["id"])
+	if platformID == "" {
+		return diag.Errorf("No 'id' field found in platform creation response")
+	}
+	d.SetId(platformID)
+	c.Lookups.invalidate("platform")
+
+	return append(diags, resourcePlatformRead(ctx, d, m)...)
+}
+
+// -------------------------------------------------------------------
+// Read
+// -------------------------------------------------------------------
+func resourcePlatformRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	var diags diag.Diagnostics
+
+	var result map[string]interface{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/api/v1/assets/platforms/%s/", d.Id()), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return apiDiagnostics(err, "Failed to read platform", nil)
+	}
+
+	d.Set("name", result["name"])
+	d.Set("category", flattenChoice(result["category"]))
+	d.Set("type", flattenChoice(result["type"]))
+	d.Set("charset", flattenChoice(result["charset"]))
+	d.Set("domain_enabled", result["domain_enabled"])
+	d.Set("su_enabled", result["su_enabled"])
+	d.Set("su_method", flattenChoice(result["su_method"]))
+	d.Set("comment", result["comment"])
+	d.Set("internal", result["internal"])
+	if protocols, ok := result["protocols"].([]interface{}); ok {
+		d.Set("protocols", flattenPlatformProtocols(protocols))
+	}
+	if automation, ok := result["automation"].(map[string]interface{}); ok {
+		if _, configured := d.GetOk("automation"); configured {
+			d.Set("automation", flattenPlatformAutomation(automation))
+		}
+	}
+
+	return diags
+}
+
+// -------------------------------------------------------------------
+// Update
+// -------------------------------------------------------------------
+func resourcePlatformUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+
+	if err := c.Client.Patch(ctx, fmt.Sprintf("/api/v1/assets/platforms/%s/", d.Id()), expandPlatform(d), nil); err != nil {
+		return apiDiagnostics(err, "Failed to update platform", nil)
+	}
+	if d.HasChange("name") {
+		c.Lookups.invalidate("platform")
+	}
+
+	return resourcePlatformRead(ctx, d, m)
+}
+
+// -------------------------------------------------------------------
+// Delete
+// -------------------------------------------------------------------
+func resourcePlatformDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Config)
+	var diags diag.Diagnostics
+
+	if err := c.Client.Delete(ctx, fmt.Sprintf("/api/v1/assets/platforms/%s/", d.Id())); err != nil {
+		return apiDiagnostics(err, "Failed to delete platform", nil)
+	}
+	c.Lookups.invalidate("platform")
+
+	d.SetId("")
+	return diags
+}
+
+// expandPlatform builds the platform payload. category and type cannot be
+// changed once the platform exists and are only sent on creation.
+func expandPlatform(d *schema.ResourceData) map[string]interface{} {
+	platformData := map[string]interface{}{
+		"name":           d.Get("name").(string),
+		"charset":        d.Get("charset").(string),
+		"domain_enabled": d.Get("domain_enabled").(bool),
+		"su_enabled":     d.Get("su_enabled").(bool),
+		"comment":        d.Get("comment").(string),
+		"protocols":      expandPlatformProtocols(d.Get("protocols").([]interface{})),
+	}
+	if v, ok := d.GetOk("su_method"); ok {
+		platformData["su_method"] = v.(string)
+	}
+	if v, ok := d.GetOk("automation"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		platformData["automation"] = v.([]interface{})[0].(map[string]interface{})
+	}
+	return platformData
+}
+
+func expandPlatformProtocols(list []interface{}) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for _, item := range list {
+		m := item.(map[string]interface{})
+		result = append(result, map[string]interface{}{
+			"name":     m["name"].(string),
+			"port":     m["port"].(int),
+			"primary":  m["primary"].(bool),
+			"required": m["required"].(bool),
+			"default":  m["default"].(bool),
+			"public":   m["public"].(bool),
+		})
+	}
+	return result
+}
+
+func flattenPlatformProtocols(protocols []interface{}) []interface{} {
+	var result []interface{}
+	for _, p := range protocols {
+		m, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"name":     m["name"],
+			"port":     m["port"],
+			"primary":  m["primary"],
+			"required": m["required"],
+			"default":  m["default"],
+			"public":   m["public"],
+		})
+	}
+	return result
+}
+
+func flattenPlatformAutomation(automation map[string]interface{}) []interface{} {
+	result := map[string]interface{}{}
+	for _, flag := range platformAutomationFlags {
+		if v, ok := automation[flag].(bool); ok {
+			result[flag] = v
+		}
+	}
+	return []interface{}{result}
+}
+
+// findPlatformID resolves a platform name to its ID through the lookup
+// cache. Platforms have numeric IDs.
+func findPlatformID(ctx context.Context, c *Config, api *client.Client, name string) (int, error) {
+	id, err := c.Lookups.get(ctx, api, "platform", name, func(ctx context.Context, api *client.Client, name string) (string, error) {
+		platformID, err := findPlatformIDByName(ctx, api, name)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(platformID), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
@@ -1,12 +1,11 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -17,8 +16,18 @@ func resourceSystemUser() *schema.Resource {
 		ReadContext:   resourceSystemUserRead,
 		UpdateContext: resourceSystemUserUpdate,
 		DeleteContext: resourceSystemUserDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -92,7 +101,7 @@ func resourceSystemUser() *schema.Resource {
 }
 
 func resourceSystemUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
@@ -114,45 +123,16 @@ func resourceSystemUserCreate(ctx context.Context, d *schema.ResourceData, m int
 		"su_enabled":              d.Get("su_enabled").(bool),
 	}
 
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
 	// Send POST request to create system user
-	url := fmt.Sprintf("%s/api/v1/assets/system-users/", c.BaseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		return diag.Errorf("Failed to create system user. Status code: %d", resp.StatusCode)
-	}
-
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Post(ctx, "/api/v1/assets/system-users/", payload, &result); err != nil {
+		return apiDiagnostics(err, "Failed to create system user", nil)
 	}
 
 	// Set resource ID
 	if id, ok := result["id"].(string); ok {
 		d.SetId(id)
+		setOrgID(d, api, result)
 	} else {
 		return diag.Errorf("Failed to retrieve ID for created system user")
 	}
@@ -161,39 +141,18 @@ func resourceSystemUserCreate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceSystemUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/system-users/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error fetching system user: %s", resp.Status)
-	}
-
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/assets/system-users/%s/", id), &result); err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return apiDiagnostics(err, "Error fetching system user", nil)
 	}
 
 	d.Set("name", result["name"].(string))
@@ -217,13 +176,14 @@ func resourceSystemUserRead(ctx context.Context, d *schema.ResourceData, m inter
 		d.Set("shell", shell)
 	}
 
+	setOrgID(d, api, result)
 	d.SetId(id)
 
 	return diags
 }
 
 func resourceSystemUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
@@ -245,35 +205,9 @@ func resourceSystemUserUpdate(ctx context.Context, d *schema.ResourceData, m int
 		"su_enabled":              d.Get("su_enabled").(bool),
 	}
 
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/system-users/%s/", c.BaseURL, id)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Failed to update system user. Status code: %d", resp.StatusCode)
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/assets/system-users/%s/", id), payload, nil); err != nil {
+		return apiDiagnostics(err, "Failed to update system user", nil)
 	}
 
 	// Update Terraform state after successful update
@@ -283,34 +217,13 @@ func resourceSystemUserUpdate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceSystemUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/assets/system-users/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusNoContent {
-		return diag.Errorf("Failed to delete system user. Status code: %d", resp.StatusCode)
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/assets/system-users/%s/", id)); err != nil {
+		return apiDiagnostics(err, "Failed to delete system user", nil)
 	}
 
 	d.SetId("") // Mark resource as deleted
@@ -1,13 +1,11 @@
 package jumpserver
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
-	"net/http"
+	"time"
 
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -18,8 +16,18 @@ func resourceUser() *schema.Resource {
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithOrg,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
+			"org_id": orgIDSchema(),
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -46,7 +54,7 @@ func resourceUser() *schema.Resource {
 }
 
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
@@ -58,47 +66,14 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 		"system_roles": d.Get("system_roles").([]interface{}),
 	}
 
-	url := c.BaseURL + "/api/v1/users/users/"
-	jsonValue, _ := json.Marshal(user)
-
-	// Log request body
-	log.Printf("Request Body: %s\n", string(jsonValue))
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 201 Created status code
-	if resp.StatusCode != http.StatusCreated {
-		return diag.Errorf("Error creating user: %s", resp.Status)
-	}
-
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return diag.FromErr(err)
+	if err := api.Post(ctx, "/api/v1/users/users/", user, &result); err != nil {
+		return apiDiagnostics(err, "Error creating user", nil)
 	}
 
-	// Log the entire response
-	log.Printf("Response Body: %v\n", result)
-
 	if id, ok := result["id"].(string); ok {
 		d.SetId(id)
+		setOrgID(d, api, result)
 	} else {
 		return diag.Errorf("Error retrieving user ID after creation, response: %v", result)
 	}
@@ -109,42 +84,17 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
-	url := fmt.Sprintf("%s/api/v1/users/users/%s/", c.BaseURL, d.Id())
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
+	var user map[string]interface{}
+	if err := api.Get(ctx, fmt.Sprintf("/api/v1/users/users/%s/", d.Id()), &user); err != nil {
+		if client.IsNotFound(err) {
 			d.SetId("")
 			return nil
 		}
-		return diag.Errorf("Error reading user: %s", resp.Status)
-	}
-
-	var user map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return diag.FromErr(err)
+		return apiDiagnostics(err, "Error reading user", nil)
 	}
 
 	d.Set("name", user["name"].(string))
@@ -152,12 +102,13 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	d.Set("email", user["email"].(string))
 	d.Set("is_active", user["is_active"].(bool))
 	d.Set("system_roles", user["system_roles"].([]interface{}))
+	setOrgID(d, api, user)
 
 	return diags
 }
 
 func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
@@ -169,30 +120,8 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		"system_roles": d.Get("system_roles").([]interface{}),
 	}
 
-	url := fmt.Sprintf("%s/api/v1/users/users/%s/", c.BaseURL, d.Id())
-	jsonValue, _ := json.Marshal(user)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return diag.Errorf("Error updating user: %s", resp.Status)
+	if err := api.Put(ctx, fmt.Sprintf("/api/v1/users/users/%s/", d.Id()), user, nil); err != nil {
+		return apiDiagnostics(err, "Error updating user", nil)
 	}
 
 	resourceUserRead(ctx, d, m)
@@ -201,35 +130,13 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*Config)
+	api := orgClient(m.(*Config), d)
 
 	var diags diag.Diagnostics
 
 	id := d.Id()
-	url := fmt.Sprintf("%s/api/v1/users/users/%s/", c.BaseURL, id)
-
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else {
-		if err := signReq(req, c.AccessKey, c.SecretKey); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	defer resp.Body.Close()
-
-	// Check for 204 No Content status code
-	if resp.StatusCode != http.StatusNoContent {
-		return diag.Errorf("Error deleting user: %s", resp.Status)
+	if err := api.Delete(ctx, fmt.Sprintf("/api/v1/users/users/%s/", id)); err != nil {
+		return apiDiagnostics(err, "Error deleting user", nil)
 	}
 
 	d.SetId("") // Mark resource as destroyed
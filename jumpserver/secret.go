@@ -0,0 +1,91 @@
+package jumpserver
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// hashSecret is the StateFunc of secret attributes: state keeps a SHA-256
+// hash of the secret rather than the secret itself, which is still enough to
+// notice when the configured secret changes.
+func hashSecret(v interface{}) string {
+	secret, _ := v.(string)
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hashedSecret returns the state value of a secret read from ResourceData,
+// which is the configured secret while applying and its hash otherwise.
+func hashedSecret(v interface{}) string {
+	secret, _ := v.(string)
+	if hash, ok := strings.CutPrefix(secret, "sha256:"); ok && len(hash) == 2*sha256.Size {
+		if _, err := hex.DecodeString(hash); err == nil {
+			return secret
+		}
+	}
+	return hashSecret(secret)
+}
+
+// sshKeyPair is a key pair generated by the provider. Only the private key
+// is uploaded to JumpServer; the public key and its fingerprint are kept in
+// state so the key can be authorized on the assets.
+type sshKeyPair struct {
+	privateKeyPEM string
+	publicKey     string
+	fingerprint   string
+}
+
+// generateSSHKey generates an ed25519 or RSA key pair. The private key is
+// encoded in a format paramiko, which JumpServer uses, can load: OpenSSH for
+// ed25519 and PKCS#1 for RSA.
+func generateSSHKey(algorithm string, bits int) (*sshKeyPair, error) {
+	var (
+		publicKey crypto.PublicKey
+		block     *pem.Block
+	)
+	switch algorithm {
+	case "ed25519":
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if block, err = ssh.MarshalPrivateKey(priv, ""); err != nil {
+			return nil, err
+		}
+		publicKey = pub
+
+	case "rsa":
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		publicKey = &key.PublicKey
+
+	default:
+		return nil, fmt.Errorf("unsupported SSH key algorithm %q", algorithm)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &sshKeyPair{
+		privateKeyPEM: string(pem.EncodeToMemory(block)),
+		publicKey:     strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPublicKey)), "\n"),
+		fingerprint:   ssh.FingerprintSHA256(sshPublicKey),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package jumpserver
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateSSHKey(t *testing.T) {
+	for _, tc := range []struct {
+		algorithm string
+		bits      int
+		keyType   string
+		pemType   string
+	}{
+		{"ed25519", 0, ssh.KeyAlgoED25519, "OPENSSH PRIVATE KEY"},
+		{"rsa", 2048, ssh.KeyAlgoRSA, "RSA PRIVATE KEY"},
+	} {
+		key, err := generateSSHKey(tc.algorithm, tc.bits)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.algorithm, err)
+		}
+		if !strings.HasPrefix(key.privateKeyPEM, "-----BEGIN "+tc.pemType+"-----") {
+			t.Errorf("%s: private key is not a %s PEM block", tc.algorithm, tc.pemType)
+		}
+
+		signer, err := ssh.ParsePrivateKey([]byte(key.privateKeyPEM))
+		if err != nil {
+			t.Fatalf("%s: parsing private key: %v", tc.algorithm, err)
+		}
+		public := signer.PublicKey()
+		if public.Type() != tc.keyType {
+			t.Errorf("%s: got key type %s, want %s", tc.algorithm, public.Type(), tc.keyType)
+		}
+		if want := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public))); key.publicKey != want {
+			t.Errorf("%s: got public key %q, want %q", tc.algorithm, key.publicKey, want)
+		}
+		if want := ssh.FingerprintSHA256(public); key.fingerprint != want || !strings.HasPrefix(key.fingerprint, "SHA256:") {
+			t.Errorf("%s: got fingerprint %q, want %q", tc.algorithm, key.fingerprint, want)
+		}
+
+		authorized, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.publicKey))
+		if err != nil {
+			t.Fatalf("%s: parsing public key: %v", tc.algorithm, err)
+		}
+		if ssh.FingerprintSHA256(authorized) != key.fingerprint {
+			t.Errorf("%s: public key and fingerprint do not match", tc.algorithm)
+		}
+	}
+
+	if _, err := generateSSHKey("dsa", 0); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+}
+
+func TestHashSecret(t *testing.T) {
+	if got := hashSecret(""); got != "" {
+		t.Errorf("got %q for an empty secret, want an empty string", got)
+	}
+	want := "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+	if got := hashSecret("secret"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
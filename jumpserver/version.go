@@ -0,0 +1,211 @@
+package jumpserver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gustavo-bolis/terraform-provider-jumpserver/jumpserver/client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// serverVersion is the version of the JumpServer the provider talks to. The
+// zero value means it could not be detected, in which case no compatibility
+// check is made.
+type serverVersion struct {
+	raw   string
+	major int
+	minor int
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)(?:\.(\d+))?`)
+
+func parseServerVersion(s string) serverVersion {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return serverVersion{}
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return serverVersion{raw: strings.TrimPrefix(strings.TrimSpace(s), "v"), major: major, minor: minor}
+}
+
+func (v serverVersion) known() bool {
+	return v.major > 0
+}
+
+func (v serverVersion) String() string {
+	if !v.known() {
+		return "unknown"
+	}
+	return v.raw
+}
+
+// detectServerVersion reads the JumpServer version from the public
+// settings. Servers that do not publish it are told apart by the asset API
+// they serve: v3 replaced system users with hosts and accounts.
+func detectServerVersion(ctx context.Context, api *client.Client) serverVersion {
+	var settings map[string]interface{}
+	if err := api.Get(ctx, "/api/v1/settings/public/", &settings); err == nil {
+		if data, ok := settings["data"].(map[string]interface{}); ok {
+			settings = data
+		}
+		if v := settingsVersion(settings); v.known() {
+			return v
+		}
+	}
+
+	probes := []struct {
+		path    string
+		version string
+	}{
+		{"/api/v1/assets/hosts/?limit=1", "3"},
+		{"/api/v1/assets/system-users/?limit=1", "2"},
+	}
+	for _, probe := range probes {
+		err := api.Get(ctx, probe.path, nil)
+		if err == nil {
+			return parseServerVersion(probe.version)
+		}
+		if !client.IsNotFound(err) {
+			tflog.Warn(ctx, "Could not detect the JumpServer version", map[string]interface{}{"error": err.Error()})
+			break
+		}
+	}
+	return serverVersion{}
+}
+
+// versionSettings are the public settings keys JumpServer publishes its
+// version under, in order of preference. Other keys mentioning a version,
+// such as the version of a client download, are ignored.
+var versionSettings = []string{"VERSION", "CURRENT_VERSION", "JUMPSERVER_VERSION"}
+
+// settingsVersion returns the version found in the public settings.
+func settingsVersion(settings map[string]interface{}) serverVersion {
+	for _, key := range versionSettings {
+		for k, value := range settings {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			if s, ok := value.(string); ok {
+				if v := parseServerVersion(s); v.known() {
+					return v
+				}
+			}
+		}
+	}
+	return serverVersion{}
+}
+
+// serverSupport describes the range of JumpServer major versions an object
+// type works with. A zero bound is open.
+type serverSupport struct {
+	minMajor int
+	maxMajor int
+	// hint tells users what to do instead on unsupported servers.
+	hint string
+}
+
+func (s serverSupport) check(name string, v serverVersion) diag.Diagnostics {
+	if !v.known() || (s.minMajor == 0 || v.major >= s.minMajor) && (s.maxMajor == 0 || v.major <= s.maxMajor) {
+		return nil
+	}
+
+	var detail string
+	switch {
+	case s.minMajor != 0 && s.maxMajor != 0:
+		detail = fmt.Sprintf("%s requires JumpServer v%d to v%d", name, s.minMajor, s.maxMajor)
+	case s.minMajor != 0:
+		detail = fmt.Sprintf("%s requires JumpServer v%d or later", name, s.minMajor)
+	default:
+		detail = fmt.Sprintf("%s requires JumpServer v%d or earlier", name, s.maxMajor)
+	}
+	detail = fmt.Sprintf("%s, but the provider is connected to JumpServer v%s.", detail, v)
+	if s.hint != "" {
+		detail += " " + s.hint
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s is not supported by this JumpServer version", name),
+		Detail:   detail,
+	}}
+}
+
+// requireServerVersion makes every operation of the resource or data source
+// r fail with a clear diagnostic when the server version is not supported,
+// rather than with whatever error the missing API would return. Plans fail
+// too, so nothing is attempted against an incompatible server.
+func requireServerVersion(name string, support serverSupport, r *schema.Resource) *schema.Resource {
+	guard := func(m interface{}) diag.Diagnostics {
+		c, ok := m.(*Config)
+		if !ok {
+			return nil
+		}
+		return support.check(name, c.ServerVersion)
+	}
+	wrap := func(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if diags := guard(m); diags != nil {
+				return diags
+			}
+			return f(ctx, d, m)
+		}
+	}
+
+	r.CreateContext = wrap(r.CreateContext)
+	r.ReadContext = wrap(r.ReadContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		importState := r.Importer.StateContext
+		r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+			if diags := guard(m); diags != nil {
+				return nil, fmt.Errorf("%s", diags[0].Detail)
+			}
+			return importState(ctx, d, m)
+		}
+	}
+
+	if r.CreateContext != nil {
+		customizeDiff := r.CustomizeDiff
+		r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			if diags := guard(m); diags != nil {
+				return fmt.Errorf("%s", diags[0].Detail)
+			}
+			if customizeDiff != nil {
+				return customizeDiff(ctx, d, m)
+			}
+			return nil
+		}
+	}
+	return r
+}
+
+var (
+	// systemUserSupport covers the system users API, replaced by accounts in
+	// JumpServer v3.
+	systemUserSupport = serverSupport{
+		maxMajor: 2,
+		hint:     "System users were replaced by accounts in JumpServer v3; manage them with the accounts of jumpserver_host instead.",
+	}
+	// assetTypeSupport covers the typed asset APIs (hosts, gateways, ...)
+	// introduced in JumpServer v3.
+	assetTypeSupport = serverSupport{
+		minMajor: 3,
+		hint:     "Use jumpserver_asset on earlier versions.",
+	}
+	// accountSupport covers the accounts API, which replaced system users in
+	// JumpServer v3.
+	accountSupport = serverSupport{
+		minMajor: 3,
+		hint:     "Use jumpserver_system_user on earlier versions.",
+	}
+)
@@ -0,0 +1,40 @@
+package jumpserver
+
+import "testing"
+
+func TestSettingsVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings map[string]interface{}
+		want     string
+	}{
+		{
+			name: "several version keys",
+			settings: map[string]interface{}{
+				"CLIENT_VERSION":     "1.1.9",
+				"XRDP_VERSION":       "2.0.1",
+				"CURRENT_VERSION":    "v3.10.4",
+				"VERSION_CHECK_TIME": "2024-01-01",
+				"JUMPSERVER_VERSION": "v2.28.0",
+			},
+			want: "3.10.4",
+		},
+		{
+			name:     "lower case key",
+			settings: map[string]interface{}{"version": "v2.28.8", "client_version": "1.1.9"},
+			want:     "2.28.8",
+		},
+		{
+			name:     "no version key",
+			settings: map[string]interface{}{"CLIENT_VERSION": "1.1.9"},
+			want:     "unknown",
+		},
+	} {
+		// Map order is random, so repeat to catch nondeterminism.
+		for i := 0; i < 20; i++ {
+			if got := settingsVersion(tc.settings).String(); got != tc.want {
+				t.Fatalf("%s: got version %s, want %s", tc.name, got, tc.want)
+			}
+		}
+	}
+}